@@ -0,0 +1,58 @@
+package capi
+
+/*
+#include <zlib.h>
+*/
+import "C"
+
+import "unsafe"
+
+// Adler32 updates a running Adler-32 checksum with the bytes in data.
+// The initial value of a running Adler-32 checksum is 1.
+// For more details, see http://zlib.net/manual.html#Checksum
+func Adler32(adler uint32, data []byte) uint32 {
+	return uint32(C.adler32_z(C.uLong(adler), bytesPointer(data), C.z_size_t(len(data))))
+}
+
+// Adler32Combine combines two Adler-32 checksums into one.
+// adler2 is the checksum of a sequence of length len2 following the sequence of adler1.
+// For more details, see http://zlib.net/manual.html#Checksum
+func Adler32Combine(adler1, adler2 uint32, len2 int64) uint32 {
+	return uint32(C.adler32_combine(C.uLong(adler1), C.uLong(adler2), C.z_off_t(len2)))
+}
+
+// Crc32 updates a running CRC-32 checksum with the bytes in data.
+// The initial value of a running CRC-32 checksum is 0.
+// For more details, see http://zlib.net/manual.html#Checksum
+func Crc32(crc uint32, data []byte) uint32 {
+	return uint32(C.crc32_z(C.uLong(crc), bytesPointer(data), C.z_size_t(len(data))))
+}
+
+// Crc32Combine combines two CRC-32 checksums into one.
+// crc2 is the checksum of a sequence of length len2 following the sequence of crc1.
+// For more details, see http://zlib.net/manual.html#Checksum
+func Crc32Combine(crc1, crc2 uint32, len2 int64) uint32 {
+	return uint32(C.crc32_combine(C.uLong(crc1), C.uLong(crc2), C.z_off_t(len2)))
+}
+
+// Crc32CombineGen returns the operator used by Crc32CombineOp to combine CRC-32 checksums
+// where the second sequence has length len2. It is faster when the same length is combined many times.
+// For more details, see http://zlib.net/manual.html#Checksum
+func Crc32CombineGen(len2 int64) uint32 {
+	return uint32(C.crc32_combine_gen(C.z_off_t(len2)))
+}
+
+// Crc32CombineOp combines two CRC-32 checksums like Crc32Combine using an operator generated by Crc32CombineGen.
+// For more details, see http://zlib.net/manual.html#Checksum
+func Crc32CombineOp(crc1, crc2, op uint32) uint32 {
+	return uint32(C.crc32_combine_op(C.uLong(crc1), C.uLong(crc2), C.uLong(op)))
+}
+
+// bytesPointer returns a pointer to the first byte of data or nil if data is empty.
+// The pointer is only passed to C for the duration of the call which is allowed by cgo without pinning.
+func bytesPointer(data []byte) *C.Bytef {
+	if len(data) == 0 {
+		return nil
+	}
+	return (*C.Bytef)(unsafe.Pointer(&data[0]))
+}
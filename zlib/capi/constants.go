@@ -45,6 +45,14 @@ const (
 	Z_FIXED            ZConstant = C.Z_FIXED
 	Z_DEFAULT_STRATEGY ZConstant = C.Z_DEFAULT_STRATEGY
 
+	// Possible values of the data_type field for deflate
+	Z_BINARY  ZConstant = C.Z_BINARY
+	Z_TEXT    ZConstant = C.Z_TEXT
+	Z_UNKNOWN ZConstant = C.Z_UNKNOWN
+
 	// The deflate compression method (the only one supported in this version)
 	Z_DEFLATED ZConstant = C.Z_DEFLATED
 )
+
+// MaxWindowSize is the size of the largest sliding window used by deflate and inflate.
+const MaxWindowSize = 1 << 15
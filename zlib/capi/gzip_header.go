@@ -0,0 +1,152 @@
+package capi
+
+/*
+#include <stdlib.h>
+#include <zlib.h>
+*/
+import "C"
+
+import "unsafe"
+
+// The maximum sizes of the gzip header fields that are kept when reading a gzip header.
+// Longer fields are truncated by zlib. See inflateGetHeader() for details.
+const (
+	GzipHeaderExtraMax   = 1 << 16
+	GzipHeaderNameMax    = 1 << 10
+	GzipHeaderCommentMax = 1 << 10
+)
+
+// GzipHeader is the Go representation of zlib's gz_header.
+// For more details, see http://zlib.net/manual.html#Advanced
+type GzipHeader struct {
+	// Text is true if the compressed data is believed to be text.
+	Text bool
+	// Time is the modification time in seconds since the Unix epoch.
+	Time uint32
+	// XFlags are the extra flags. They are not used when writing a gzip header.
+	XFlags int
+	// OS is the operating system code.
+	OS int
+	// Extra is the extra field or nil if none.
+	Extra []byte
+	// Name is the file name or empty if none.
+	Name string
+	// Comment is the comment or empty if none.
+	Comment string
+	// HCRC is true if there was or will be a header crc.
+	HCRC bool
+}
+
+// cGzipHeader is a gz_header allocated in C memory.
+// zlib keeps a pointer to the gz_header and its buffers across calls to deflate() and inflate().
+// Therefore, they can not live in Go memory.
+// The buffers are tracked separately because inflate() sets the pointers in gz_header to
+// Z_NULL when the corresponding fields are missing.
+type cGzipHeader struct {
+	head    *C.gz_header
+	extra   *C.Bytef
+	name    *C.Bytef
+	comment *C.Bytef
+}
+
+// newCGzipHeaderForWriting allocates a gz_header holding the fields of header.
+func newCGzipHeaderForWriting(header *GzipHeader) *cGzipHeader {
+	h := &cGzipHeader{
+		head: (*C.gz_header)(C.calloc(1, C.sizeof_gz_header)),
+	}
+	h.head.text = cBool(header.Text)
+	h.head.time = C.uLong(header.Time)
+	h.head.xflags = C.int(header.XFlags)
+	h.head.os = C.int(header.OS)
+	h.head.hcrc = cBool(header.HCRC)
+
+	if header.Extra != nil {
+		h.extra = (*C.Bytef)(C.CBytes(header.Extra))
+		h.head.extra = h.extra
+		h.head.extra_len = C.uInt(len(header.Extra))
+	}
+	if header.Name != "" {
+		h.name = (*C.Bytef)(unsafe.Pointer(C.CString(header.Name)))
+		h.head.name = h.name
+	}
+	if header.Comment != "" {
+		h.comment = (*C.Bytef)(unsafe.Pointer(C.CString(header.Comment)))
+		h.head.comment = h.comment
+	}
+	return h
+}
+
+// newCGzipHeaderForReading allocates a gz_header with enough space to receive the header fields.
+func newCGzipHeaderForReading() *cGzipHeader {
+	h := &cGzipHeader{
+		head:    (*C.gz_header)(C.calloc(1, C.sizeof_gz_header)),
+		extra:   (*C.Bytef)(C.calloc(1, GzipHeaderExtraMax)),
+		name:    (*C.Bytef)(C.calloc(1, GzipHeaderNameMax)),
+		comment: (*C.Bytef)(C.calloc(1, GzipHeaderCommentMax)),
+	}
+	h.head.extra = h.extra
+	h.head.extra_max = GzipHeaderExtraMax
+	h.head.name = h.name
+	h.head.name_max = GzipHeaderNameMax
+	h.head.comment = h.comment
+	h.head.comm_max = GzipHeaderCommentMax
+	return h
+}
+
+// done returns the done field of gz_header.
+// It is 1 when inflate() is done reading the gzip header, -1 if the stream has no gzip header, and 0 otherwise.
+func (h *cGzipHeader) done() int {
+	return int(h.head.done)
+}
+
+// toGo copies the gz_header into Go memory.
+func (h *cGzipHeader) toGo() *GzipHeader {
+	header := &GzipHeader{
+		Text:   h.head.text != 0,
+		Time:   uint32(h.head.time),
+		XFlags: int(h.head.xflags),
+		OS:     int(h.head.os),
+		HCRC:   h.head.hcrc != 0,
+	}
+	if h.head.extra != nil {
+		length := h.head.extra_len
+		if length > h.head.extra_max {
+			length = h.head.extra_max
+		}
+		header.Extra = C.GoBytes(unsafe.Pointer(h.head.extra), C.int(length))
+	}
+	if h.head.name != nil {
+		header.Name = goStringN(h.head.name, int(h.head.name_max))
+	}
+	if h.head.comment != nil {
+		header.Comment = goStringN(h.head.comment, int(h.head.comm_max))
+	}
+	return header
+}
+
+// free releases the C memory held by the gz_header.
+func (h *cGzipHeader) free() {
+	C.free(unsafe.Pointer(h.extra))
+	C.free(unsafe.Pointer(h.name))
+	C.free(unsafe.Pointer(h.comment))
+	C.free(unsafe.Pointer(h.head))
+}
+
+// goStringN reads a zero-terminated string of at most max bytes.
+// zlib does not zero-terminate a field that was truncated.
+func goStringN(s *C.Bytef, max int) string {
+	b := C.GoBytes(unsafe.Pointer(s), C.int(max))
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+func cBool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
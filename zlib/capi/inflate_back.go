@@ -0,0 +1,117 @@
+package capi
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <zlib.h>
+
+// The callbacks are implemented in Go. See inflate_back_callbacks.go.
+extern unsigned goInflateBackIn(void *desc, unsigned char **buf);
+extern int goInflateBackOut(void *desc, unsigned char *buf, unsigned len);
+
+int InflateBackInit(z_streamp strm, int windowBits, unsigned char *window) {
+	return inflateBackInit(strm, windowBits, window);
+}
+
+int InflateBack(z_streamp strm, uintptr_t desc) {
+	return inflateBack(strm, goInflateBackIn, (void *)desc, goInflateBackOut, (void *)desc);
+}
+
+int InflateBackEnd(z_streamp strm) {
+	return inflateBackEnd(strm);
+}
+*/
+import "C"
+
+import (
+	"runtime"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// InflateBackIn provides input to InflateBack.
+// It fills buf with compressed data and returns the number of bytes written to buf.
+// It returns 0 when there is no more input which makes InflateBack return Z_BUF_ERROR.
+type InflateBackIn func(buf []byte) int
+
+// InflateBackOut receives the output of InflateBack.
+// p is only valid during the call. It returns false to abort InflateBack which then returns Z_BUF_ERROR.
+type InflateBackOut func(p []byte) bool
+
+// inflateBackInputSize is the size of the input buffer provided to InflateBackIn.
+const inflateBackInputSize = 1 << 16
+
+// inflateBackCallbacks is passed to the C callbacks through a cgo.Handle.
+type inflateBackCallbacks struct {
+	in  InflateBackIn
+	out InflateBackOut
+
+	// input is the buffer filled by in. It is allocated in C memory because
+	// zlib keeps a pointer to it after the callback returns.
+	input unsafe.Pointer
+}
+
+// InflateBackInit initializes the internal stream state for decompression using InflateBack.
+// Only raw deflate is supported. windowBits is between 8 and 15.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateBackInit(windowBits int) ZConstant {
+	z.strm.zalloc = nil
+	z.strm.zfree = nil
+	z.strm.opaque = nil
+
+	// The window is allocated in C memory because zlib keeps a pointer to it until InflateBackEnd.
+	window := C.malloc(C.size_t(1) << windowBits)
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	ret := z.initialized(ZConstant(C.InflateBackInit(&z.strm, C.int(windowBits), (*C.uchar)(window))), zstreamInflateBack)
+	if ret != Z_OK {
+		C.free(window)
+		return ret
+	}
+	z.backWindow = window
+	return ret
+}
+
+// InflateBack decompresses a whole raw deflate stream. It calls in to get the compressed data
+// and out to write the decompressed data. It returns Z_STREAM_END on success.
+// The number of bytes provided by in after the end of the deflate stream is returned by AvailIn.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateBack(in InflateBackIn, out InflateBackOut) ZConstant {
+	callbacks := &inflateBackCallbacks{
+		in:    in,
+		out:   out,
+		input: C.malloc(inflateBackInputSize),
+	}
+	defer C.free(callbacks.input)
+	handle := cgo.NewHandle(callbacks)
+	defer handle.Delete()
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	// in is called right away since there is no input.
+	z.strm.next_in = nil
+	z.strm.avail_in = 0
+	ret := ZConstant(C.InflateBack(&z.strm, C.uintptr_t(handle)))
+	// next_in points to the input buffer which is freed. avail_in is kept.
+	z.strm.next_in = nil
+	return ret
+}
+
+// InflateBackEnd frees the resources allocated by InflateBackInit.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateBackEnd() ZConstant {
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	ret := ZConstant(C.InflateBackEnd(&z.strm))
+	z.state = zstreamNotInitialized
+	C.free(z.backWindow)
+	z.backWindow = nil
+	return ret
+}
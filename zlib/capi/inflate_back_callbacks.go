@@ -0,0 +1,28 @@
+package capi
+
+// The preamble of a file using //export can only contain declarations.
+// Therefore, the callbacks are kept apart from the C wrappers in inflate_back.go.
+
+import "C"
+
+import (
+	"runtime/cgo"
+	"unsafe"
+)
+
+//export goInflateBackIn
+func goInflateBackIn(desc unsafe.Pointer, buf **C.uchar) C.uint {
+	callbacks := cgo.Handle(uintptr(desc)).Value().(*inflateBackCallbacks)
+	n := callbacks.in(unsafe.Slice((*byte)(callbacks.input), inflateBackInputSize))
+	*buf = (*C.uchar)(callbacks.input)
+	return C.uint(n)
+}
+
+//export goInflateBackOut
+func goInflateBackOut(desc unsafe.Pointer, buf *C.uchar, length C.uint) C.int {
+	callbacks := cgo.Handle(uintptr(desc)).Value().(*inflateBackCallbacks)
+	if !callbacks.out(unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(length))) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package capi
+
+/*
+#include <zlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/utils"
+)
+
+// SetLogger sets the logger of the stream. nil uses the global logger set by utils.SetLogger.
+func (z *zstream) SetLogger(logger *slog.Logger) {
+	z.logger = logger
+}
+
+// logCall logs a call to zlib with op at debug level.
+// availIn and availOut are the values before the call. The values after the call are read from the stream.
+func (z *zstream) logCall(op Op, flush ZConstant, ret ZConstant, availIn, availOut int) {
+	logger := utils.DebugLogger(z.logger)
+	if logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("stream", fmt.Sprintf("%p", z)),
+		slog.String("op", string(op)),
+		slog.String("flush", flushName(flush)),
+		slog.String("ret", codeName(ret)),
+		slog.Int("avail_in_before", availIn),
+		slog.Int("avail_in", int(z.strm.avail_in)),
+		slog.Int("avail_out_before", availOut),
+		slog.Int("avail_out", int(z.strm.avail_out)),
+	}
+	if ret < 0 && z.strm.msg != nil {
+		attrs = append(attrs, slog.String("msg", C.GoString(z.strm.msg)))
+	}
+	logger.LogAttrs(context.Background(), slog.LevelDebug, "zlib call", attrs...)
+}
+
+func flushName(flush ZConstant) string {
+	switch flush {
+	case Z_NO_FLUSH:
+		return "Z_NO_FLUSH"
+	case Z_PARTIAL_FLUSH:
+		return "Z_PARTIAL_FLUSH"
+	case Z_SYNC_FLUSH:
+		return "Z_SYNC_FLUSH"
+	case Z_FULL_FLUSH:
+		return "Z_FULL_FLUSH"
+	case Z_FINISH:
+		return "Z_FINISH"
+	case Z_BLOCK:
+		return "Z_BLOCK"
+	case Z_TREES:
+		return "Z_TREES"
+	default:
+		return fmt.Sprintf("%d", flush)
+	}
+}
@@ -0,0 +1,32 @@
+package capi
+
+/*
+#include <zlib.h>
+*/
+import "C"
+
+// CompressBound returns an upper bound on the size of the output of Compress2 for sourceLength bytes.
+// For more details, see http://zlib.net/manual.html#Utility
+func CompressBound(sourceLength int) int {
+	return int(C.compressBound(C.uLong(sourceLength)))
+}
+
+// Compress2 compresses source into dest as a zlib stream in one call and returns the number of bytes written to dest.
+// dest must be large enough to hold the whole output, which is guaranteed with CompressBound.
+// For more details, see http://zlib.net/manual.html#Utility
+func Compress2(dest, source []byte, level int) (int, ZConstant) {
+	destLength := C.uLongf(len(dest))
+	ret := C.compress2(bytesPointer(dest), &destLength, bytesPointer(source), C.uLong(len(source)), C.int(level))
+	return int(destLength), ZConstant(ret)
+}
+
+// Uncompress2 decompresses the zlib stream in source into dest in one call.
+// It returns the number of bytes written to dest and the number of bytes consumed from source.
+// Z_BUF_ERROR is returned when dest is not large enough to hold the whole output.
+// For more details, see http://zlib.net/manual.html#Utility
+func Uncompress2(dest, source []byte) (int, int, ZConstant) {
+	destLength := C.uLongf(len(dest))
+	sourceLength := C.uLong(len(source))
+	ret := C.uncompress2(bytesPointer(dest), &destLength, bytesPointer(source), &sourceLength)
+	return int(destLength), int(sourceLength), ZConstant(ret)
+}
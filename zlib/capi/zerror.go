@@ -1,28 +1,127 @@
 package capi
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
+// Sentinel errors for the return codes of zlib.
+// A *ZlibError wraps the sentinel of its code so that it can be matched with errors.Is.
+var (
+	ErrStreamEnd = errors.New("zlib: Z_STREAM_END")
+	ErrNeedDict  = errors.New("zlib: Z_NEED_DICT")
+	ErrErrno     = errors.New("zlib: Z_ERRNO")
+	ErrStream    = errors.New("zlib: Z_STREAM_ERROR")
+	ErrData      = errors.New("zlib: Z_DATA_ERROR")
+	ErrMem       = errors.New("zlib: Z_MEM_ERROR")
+	ErrBuf       = errors.New("zlib: Z_BUF_ERROR")
+	ErrVersion   = errors.New("zlib: Z_VERSION_ERROR")
+)
+
+// Op is the zlib operation that returned an error.
+type Op string
+
+const (
+	OpInit          Op = "init"
+	OpDeflate       Op = "deflate"
+	OpInflate       Op = "inflate"
+	OpSetDictionary Op = "setDictionary"
+	OpGetDictionary Op = "getDictionary"
+	OpSetHeader     Op = "setHeader"
+	OpGetHeader     Op = "getHeader"
+	OpReset         Op = "reset"
+	OpParams        Op = "params"
+	OpTune          Op = "tune"
+	OpPrime         Op = "prime"
+	OpPending       Op = "pending"
+	OpSync          Op = "sync"
+	OpEnd           Op = "end"
+	OpInflateBack   Op = "inflateBack"
+	OpCompress      Op = "compress"
+	OpUncompress    Op = "uncompress"
+)
+
+// ZlibError is an error returned by zlib.
+// It can be matched against the sentinel of its code, for example errors.Is(err, ErrData).
+type ZlibError struct {
+	// Code is the return code of zlib.
+	Code ZConstant
+	// Op is the operation that returned the error. It is empty when unknown.
+	Op Op
+	// Msg is the message set by zlib in strm.msg, for example "incorrect header check". It is empty when zlib did not set it.
+	Msg string
+}
+
+func (e *ZlibError) Error() string {
+	s := "zlib: "
+	if e.Op != "" {
+		s += string(e.Op) + ": "
+	}
+	if e.Msg != "" {
+		return s + e.Msg + " (" + codeName(e.Code) + ")"
+	}
+	return s + codeName(e.Code)
+}
+
+// Unwrap returns the sentinel error of the code.
+func (e *ZlibError) Unwrap() error {
+	switch e.Code {
+	case Z_STREAM_END:
+		return ErrStreamEnd
+	case Z_NEED_DICT:
+		return ErrNeedDict
+	case Z_ERRNO:
+		return ErrErrno
+	case Z_STREAM_ERROR:
+		return ErrStream
+	case Z_DATA_ERROR:
+		return ErrData
+	case Z_MEM_ERROR:
+		return ErrMem
+	case Z_BUF_ERROR:
+		return ErrBuf
+	case Z_VERSION_ERROR:
+		return ErrVersion
+	default:
+		return nil
+	}
+}
+
+// ZError returns the error for the return code ret, or nil for Z_OK.
+// Prefer ZStream.Error which also records the operation and the message of zlib.
 func ZError(ret ZConstant) error {
+	return ZErrorOp("", ret)
+}
+
+// ZErrorOp returns the error for the return code ret of op, or nil for Z_OK.
+func ZErrorOp(op Op, ret ZConstant) error {
+	if ret == Z_OK {
+		return nil
+	}
+	return &ZlibError{Code: ret, Op: op}
+}
+
+func codeName(ret ZConstant) string {
 	switch ret {
 	case Z_OK:
-		return nil
+		return "Z_OK"
 	case Z_STREAM_END:
-		return fmt.Errorf("zlib: Z_STREAM_END")
+		return "Z_STREAM_END"
 	case Z_NEED_DICT:
-		return fmt.Errorf("zlib: Z_NEED_DICT")
+		return "Z_NEED_DICT"
 	case Z_ERRNO:
-		return fmt.Errorf("zlib: Z_ERRNO")
+		return "Z_ERRNO"
 	case Z_STREAM_ERROR:
-		return fmt.Errorf("zlib: Z_STREAM_ERROR")
+		return "Z_STREAM_ERROR"
 	case Z_DATA_ERROR:
-		return fmt.Errorf("zlib: Z_DATA_ERROR")
+		return "Z_DATA_ERROR"
 	case Z_MEM_ERROR:
-		return fmt.Errorf("zlib: Z_MEM_ERROR")
+		return "Z_MEM_ERROR"
 	case Z_BUF_ERROR:
-		return fmt.Errorf("zlib: Z_BUF_ERROR")
+		return "Z_BUF_ERROR"
 	case Z_VERSION_ERROR:
-		return fmt.Errorf("zlib: Z_VERSION_ERROR")
+		return "Z_VERSION_ERROR"
 	default:
-		return fmt.Errorf("zlib: %d", ret)
+		return fmt.Sprintf("%d", ret)
 	}
 }
@@ -36,10 +36,62 @@ int InflateSetDictionary(z_streamp strm, const Bytef *dictionary,
 	return inflateSetDictionary(strm, dictionary, dictLength);
 }
 
+int DeflateGetDictionary(z_streamp strm, Bytef *dictionary, uInt *dictLength) {
+	return deflateGetDictionary(strm, dictionary, dictLength);
+}
+
+int InflateGetDictionary(z_streamp strm, Bytef *dictionary, uInt *dictLength) {
+	return inflateGetDictionary(strm, dictionary, dictLength);
+}
+
+int DeflateSetHeader(z_streamp strm, gz_headerp head) {
+	return deflateSetHeader(strm, head);
+}
+
+int InflateGetHeader(z_streamp strm, gz_headerp head) {
+	return inflateGetHeader(strm, head);
+}
+
+int DeflateParams(z_streamp strm, int level, int strategy) {
+	return deflateParams(strm, level, strategy);
+}
+
+int DeflateTune(z_streamp strm, int good_length, int max_lazy, int nice_length, int max_chain) {
+	return deflateTune(strm, good_length, max_lazy, nice_length, max_chain);
+}
+
+int DeflatePrime(z_streamp strm, int bits, int value) {
+	return deflatePrime(strm, bits, value);
+}
+
+int DeflatePending(z_streamp strm, unsigned *pending, int *bits) {
+	return deflatePending(strm, pending, bits);
+}
+
+int InflatePrime(z_streamp strm, int bits, int value) {
+	return inflatePrime(strm, bits, value);
+}
+
+int InflateSync(z_streamp strm) {
+	return inflateSync(strm);
+}
+
+int InflateReset(z_streamp strm) {
+	return inflateReset(strm);
+}
+
 int DeflateBound(z_streamp strm, int sourceLen) {
 	return deflateBound(strm, sourceLen);
 }
 
+int DeflateReset(z_streamp strm) {
+	return deflateReset(strm);
+}
+
+int InflateReset2(z_streamp strm, int windowBits) {
+	return inflateReset2(strm, windowBits);
+}
+
 int Inflate(z_streamp strm, int flush) {
 	return inflate(strm, flush);
 }
@@ -59,9 +111,9 @@ int InflateEnd(z_streamp strm) {
 import "C"
 
 import (
+	"log/slog"
 	"runtime"
-
-	"github.com/MeenaAlfons/go-zlib/zlib/utils"
+	"unsafe"
 )
 
 type ZStream interface {
@@ -72,6 +124,17 @@ type ZStream interface {
 
 	DeflateSetDictionary(dictionary []byte) ZConstant
 	InflateSetDictionary(dictionary []byte) ZConstant
+	DeflateGetDictionary() ([]byte, ZConstant)
+	InflateGetDictionary() ([]byte, ZConstant)
+
+	DeflateSetHeader(header *GzipHeader) ZConstant
+	InflateGetHeader() ZConstant
+	GzipHeader() (*GzipHeader, bool)
+	GzipHeaderDone() int
+
+	DeflateReset() ZConstant
+	InflateReset2(windowBits int) ZConstant
+	InflateReset() ZConstant
 
 	DeflateEnd() ZConstant
 	InflateEnd() ZConstant
@@ -82,17 +145,40 @@ type ZStream interface {
 	Deflate(flush ZConstant) ZConstant
 	Inflate(flush ZConstant) ZConstant
 
+	DeflateParams(level, strategy int) ZConstant
+	DeflateTune(goodLength, maxLazy, niceLength, maxChain int) ZConstant
+	DeflatePrime(bits, value int) ZConstant
+	DeflatePending() (pendingBytes int, pendingBits int, ret ZConstant)
+	InflatePrime(bits, value int) ZConstant
+	InflateSync() ZConstant
+
+	InflateBackInit(windowBits int) ZConstant
+	InflateBack(in InflateBackIn, out InflateBackOut) ZConstant
+	InflateBackEnd() ZConstant
+
 	ProducedOutput() int
 	OutputBufferIsFull() bool
 	AvailIn() int
+	UnconsumedInput() []byte
+	TotalIn() int64
+	TotalOut() int64
+	Adler() uint32
+	DataType() int
 
 	DeflateBound(sourceLength int) int
+
+	Error(op Op, ret ZConstant) error
+
+	// SetLogger sets the logger of the stream. nil uses the global logger.
+	SetLogger(logger *slog.Logger)
 }
 
 // NewZStream creates a new ZStream representing a C z_stream
+// If the ZStream is garbage collected while it is still initialized,
+// DeflateEnd or InflateEnd is called to free the memory allocated by zlib.
 func NewZStream() ZStream {
 	z := &zstream{}
-	utils.Debug("NewZStream %p", z)
+	runtime.SetFinalizer(z, (*zstream).finalize)
 
 	return z
 }
@@ -100,11 +186,34 @@ func NewZStream() ZStream {
 // Make sure that zstream implements ZStream
 var _ ZStream = (*zstream)(nil)
 
+type zstreamState int
+
+const (
+	zstreamNotInitialized zstreamState = iota
+	zstreamDeflate
+	zstreamInflate
+	zstreamInflateBack
+)
+
 type zstream struct {
-	strm C.z_stream
+	strm  C.z_stream
+	state zstreamState
 
 	in  []byte
 	out []byte
+
+	// gzHeader is the gz_header provided to deflateSetHeader or inflateGetHeader.
+	gzHeader *cGzipHeader
+	// gzHeaderRead is a copy of gzHeader in Go memory once inflate is done reading the gzip header.
+	gzHeaderRead *GzipHeader
+	// gzHeaderDone is a copy of the done field of gzHeader so that it outlives gzHeader.
+	gzHeaderDone int
+
+	// backWindow is the window provided to inflateBackInit. It is allocated in C memory.
+	backWindow unsafe.Pointer
+
+	// logger is the logger of the stream. nil uses the global logger.
+	logger *slog.Logger
 }
 
 // InflateInit initializes the internal stream state for decompression.
@@ -120,7 +229,7 @@ func (z *zstream) InflateInit() ZConstant {
 	pinner.Pin(&z.strm)
 	defer pinner.Unpin()
 
-	return ZConstant(C.InflateInit(&z.strm))
+	return z.initialized(ZConstant(C.InflateInit(&z.strm)), zstreamInflate)
 }
 
 // InflateInit2 initializes the internal stream state for decompression.
@@ -136,7 +245,7 @@ func (z *zstream) InflateInit2(windowBits int) ZConstant {
 	pinner.Pin(&z.strm)
 	defer pinner.Unpin()
 
-	return ZConstant(C.InflateInit2(&z.strm, C.int(windowBits)))
+	return z.initialized(ZConstant(C.InflateInit2(&z.strm, C.int(windowBits))), zstreamInflate)
 }
 
 // DeflateInit initializes the internal stream state for compression.
@@ -150,7 +259,7 @@ func (z *zstream) DeflateInit(level int) ZConstant {
 	pinner.Pin(&z.strm)
 	defer pinner.Unpin()
 
-	return ZConstant(C.DeflateInit(&z.strm, C.int(level)))
+	return z.initialized(ZConstant(C.DeflateInit(&z.strm, C.int(level))), zstreamDeflate)
 }
 
 // DeflateInit2 initializes the internal stream state for compression.
@@ -164,7 +273,7 @@ func (z *zstream) DeflateInit2(level, windowBits, memLevel, strategy int) ZConst
 	pinner.Pin(&z.strm)
 	defer pinner.Unpin()
 
-	return ZConstant(C.DeflateInit2(&z.strm, C.int(level), C.int(Z_DEFLATED), C.int(windowBits), C.int(memLevel), C.int(strategy)))
+	return z.initialized(ZConstant(C.DeflateInit2(&z.strm, C.int(level), C.int(Z_DEFLATED), C.int(windowBits), C.int(memLevel), C.int(strategy))), zstreamDeflate)
 }
 
 // DeflateSetDictionary initializes the compression dictionary.
@@ -178,7 +287,6 @@ func (z *zstream) DeflateSetDictionary(dictionary []byte) ZConstant {
 	//   dictionary and causes an error "found pointer to free object".
 	dict := make([]byte, len(dictionary)+1)
 	copy(dict, dictionary)
-	utils.Debug("DeflateSetDictionary %p dict: %p len(dict): %d, dictionary: %p, len(dictionary): %d (*C.Bytef)(&dict[0]): %p", z, dict, len(dict), dictionary, len(dictionary), (*C.Bytef)(&dict[0]))
 
 	pinner := runtime.Pinner{}
 	pinner.Pin(&z.strm)
@@ -198,7 +306,6 @@ func (z *zstream) InflateSetDictionary(dictionary []byte) ZConstant {
 	//   dictionary and causes an error "found pointer to free object".
 	dict := make([]byte, len(dictionary)+1)
 	copy(dict, dictionary)
-	utils.Debug("InflateSetDictionary %p dict: %p len(dict): %d, dictionary: %p, len(dictionary): %d (*C.Bytef)(&dict[0]): %p", z, dict, len(dict), dictionary, len(dictionary), (*C.Bytef)(&dict[0]))
 
 	pinner := runtime.Pinner{}
 	pinner.Pin(&z.strm)
@@ -207,6 +314,89 @@ func (z *zstream) InflateSetDictionary(dictionary []byte) ZConstant {
 	return ZConstant(C.InflateSetDictionary(&z.strm, (*C.Bytef)(&dict[0]), C.uInt(len(dictionary))))
 }
 
+// DeflateGetDictionary returns the sliding dictionary being maintained by deflate.
+// It is at most the window size and holds the last data fed to deflate including the data not compressed yet.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflateGetDictionary() ([]byte, ZConstant) {
+	// One more byte is reserved for memory safety reasons. See InflateSetDictionary.
+	dict := make([]byte, MaxWindowSize+1)
+	var length C.uInt
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	pinner.Pin(&dict[0])
+	defer pinner.Unpin()
+
+	ret := ZConstant(C.DeflateGetDictionary(&z.strm, (*C.Bytef)(&dict[0]), &length))
+	if ret != Z_OK {
+		return nil, ret
+	}
+	return dict[:length:length], ret
+}
+
+// InflateGetDictionary returns the sliding dictionary being maintained by inflate.
+// It is at most 32 KiB and is empty before any data has been decompressed.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateGetDictionary() ([]byte, ZConstant) {
+	// One more byte is reserved for memory safety reasons. See InflateSetDictionary.
+	dict := make([]byte, MaxWindowSize+1)
+	var length C.uInt
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	pinner.Pin(&dict[0])
+	defer pinner.Unpin()
+
+	ret := ZConstant(C.InflateGetDictionary(&z.strm, (*C.Bytef)(&dict[0]), &length))
+	if ret != Z_OK {
+		return nil, ret
+	}
+	return dict[:length:length], ret
+}
+
+// DeflateSetHeader provides the gzip header information to be written when the stream uses a gzip wrapper.
+// The header is copied and can be modified by the caller after the call.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflateSetHeader(header *GzipHeader) ZConstant {
+	z.freeGzipHeader()
+	z.gzHeader = newCGzipHeaderForWriting(header)
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	return ZConstant(C.DeflateSetHeader(&z.strm, z.gzHeader.head))
+}
+
+// InflateGetHeader requests that the gzip header information is stored when a gzip header is read.
+// Once inflate is done reading the header, it can be retrieved with GzipHeader.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateGetHeader() ZConstant {
+	z.freeGzipHeader()
+	z.gzHeaderRead = nil
+	z.gzHeaderDone = 0
+	z.gzHeader = newCGzipHeaderForReading()
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	return ZConstant(C.InflateGetHeader(&z.strm, z.gzHeader.head))
+}
+
+// GzipHeader returns the gzip header read by inflate after InflateGetHeader has been called.
+// It returns false if the header has not been completely read yet or if the stream has no gzip header.
+func (z *zstream) GzipHeader() (*GzipHeader, bool) {
+	return z.gzHeaderRead, z.gzHeaderRead != nil
+}
+
+// GzipHeaderDone reports the progress of reading the gzip header after InflateGetHeader has been called.
+// It returns 1 when the gzip header has been read, -1 if the stream turned out to have no gzip header,
+// and 0 if the header has not been read yet.
+func (z *zstream) GzipHeaderDone() int {
+	return z.gzHeaderDone
+}
+
 // DeflateBound returns an upper bound on the compressed size after deflation of sourceLen bytes.
 // For more details, see http://zlib.net/manual.html#Advanced
 func (z *zstream) DeflateBound(sourceLength int) int {
@@ -217,6 +407,19 @@ func (z *zstream) DeflateBound(sourceLength int) int {
 	return int(C.DeflateBound(&z.strm, C.int(sourceLength)))
 }
 
+// Error returns the error for the return code ret of op, or nil for Z_OK.
+// The error includes the message set by zlib in strm.msg, so it must be called before the stream is used again.
+func (z *zstream) Error(op Op, ret ZConstant) error {
+	if ret == Z_OK {
+		return nil
+	}
+	err := &ZlibError{Code: ret, Op: op}
+	if ret < 0 && z.strm.msg != nil {
+		err.Msg = C.GoString(z.strm.msg)
+	}
+	return err
+}
+
 // DeflateEnd frees all dynamically allocated data structures for this stream.
 // For more details, see http://zlib.net/manual.html#Basic
 func (z *zstream) DeflateEnd() ZConstant {
@@ -224,13 +427,12 @@ func (z *zstream) DeflateEnd() ZConstant {
 	z.SetOutput(nil)
 
 	pinner := z.pin()
-	defer func() {
-		pinner.Unpin()
-		utils.Debug("DeflateEnd Unpinned")
-	}()
-	utils.Debug("DeflateEnd %p next_int: %p out: next_out %p", z, z.strm.next_in, z.strm.next_out)
+	defer pinner.Unpin()
 
-	return ZConstant(C.DeflateEnd(&z.strm))
+	ret := ZConstant(C.DeflateEnd(&z.strm))
+	z.state = zstreamNotInitialized
+	z.freeGzipHeader()
+	return ret
 }
 
 // InflateEnd frees all dynamically allocated data structures for this stream.
@@ -240,13 +442,71 @@ func (z *zstream) InflateEnd() ZConstant {
 	z.SetInput(nil)
 	z.SetOutput(nil)
 	pinner := z.pin()
-	defer func() {
-		pinner.Unpin()
-		utils.Debug("InflateEnd Unpinned")
-	}()
-	utils.Debug("InflateEnd %p next_int: %p out: next_out %p", z, z.strm.next_in, z.strm.next_out)
+	defer pinner.Unpin()
 
-	return ZConstant(C.InflateEnd(&z.strm))
+	ret := ZConstant(C.InflateEnd(&z.strm))
+	z.state = zstreamNotInitialized
+	z.freeGzipHeader()
+	return ret
+}
+
+// DeflateReset resets the stream to start a new compression with the same parameters
+// without freeing and reallocating the internal compression state.
+// A gzip header provided by DeflateSetHeader is dropped.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflateReset() ZConstant {
+	z.SetInput(nil)
+	z.SetOutput(nil)
+
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	ret := ZConstant(C.DeflateReset(&z.strm))
+	if ret == Z_OK && z.gzHeader != nil {
+		// deflateReset keeps the gzip header. Remove it before freeing it.
+		ret = ZConstant(C.DeflateSetHeader(&z.strm, nil))
+		z.freeGzipHeader()
+	}
+	return ret
+}
+
+// InflateReset2 resets the stream to start a new decompression with the given windowBits
+// without freeing and reallocating the internal decompression state unless the window size changes.
+// A gzip header requested by InflateGetHeader is dropped.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateReset2(windowBits int) ZConstant {
+	z.SetInput(nil)
+	z.SetOutput(nil)
+	return z.inflateReset(func() ZConstant {
+		return ZConstant(C.InflateReset2(&z.strm, C.int(windowBits)))
+	})
+}
+
+// InflateReset resets the stream to start a new decompression with the same window bits.
+// Unlike InflateReset2, the input and output buffers are kept so that decompression can continue
+// with the rest of the input, for example with the next member of a multi-member stream.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateReset() ZConstant {
+	return z.inflateReset(func() ZConstant {
+		return ZConstant(C.InflateReset(&z.strm))
+	})
+}
+
+// inflateReset calls reset and drops the gzip header.
+func (z *zstream) inflateReset(reset func() ZConstant) ZConstant {
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	ret := reset()
+	if ret == Z_OK {
+		// zlib no longer refers to the gzip header after a reset.
+		z.freeGzipHeader()
+		z.gzHeaderRead = nil
+		z.gzHeaderDone = 0
+	}
+	return ret
 }
 
 // SetInput sets the input buffer for the stream.
@@ -274,23 +534,92 @@ func (z *zstream) SetOutput(out []byte) {
 // Deflate compresses as much data as possible, and stops when the input buffer becomes empty or the output buffer becomes full.
 // For more details, see http://zlib.net/manual.html#Basic
 func (z *zstream) Deflate(flush ZConstant) ZConstant {
-	var ret ZConstant
-	z.wrapOp(func() {
-		ret = ZConstant(C.Deflate(&z.strm, C.int(flush)))
+	return z.wrapOp(OpDeflate, flush, func() ZConstant {
+		return ZConstant(C.Deflate(&z.strm, C.int(flush)))
 	})
-	return ret
 }
 
 // Inflate decompresses as much data as possible, and stops when the input buffer becomes empty or the output buffer becomes full.
 // For more details, see http://zlib.net/manual.html#Basic
 func (z *zstream) Inflate(flush ZConstant) ZConstant {
-	var ret ZConstant
-	z.wrapOp(func() {
-		ret = ZConstant(C.Inflate(&z.strm, C.int(flush)))
+	ret := z.wrapOp(OpInflate, flush, func() ZConstant {
+		return ZConstant(C.Inflate(&z.strm, C.int(flush)))
 	})
+	if z.gzHeader != nil && z.gzHeaderDone == 0 {
+		z.gzHeaderDone = z.gzHeader.done()
+		if z.gzHeaderDone == 1 {
+			z.gzHeaderRead = z.gzHeader.toGo()
+		}
+	}
 	return ret
 }
 
+// DeflateParams dynamically updates the compression level and compression strategy.
+// If the change requires compressing the input so far with the old parameters, deflate is called
+// with Z_BLOCK using the input and output buffers set by SetInput and SetOutput.
+// Z_BUF_ERROR is returned without changing the parameters if that could not be completed.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflateParams(level, strategy int) ZConstant {
+	// deflateParams calls deflate with Z_BLOCK when needed.
+	return z.wrapOp(OpParams, Z_BLOCK, func() ZConstant {
+		return ZConstant(C.DeflateParams(&z.strm, C.int(level), C.int(strategy)))
+	})
+}
+
+// DeflateTune fine tunes the internal compression parameters of deflate.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflateTune(goodLength, maxLazy, niceLength, maxChain int) ZConstant {
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	return ZConstant(C.DeflateTune(&z.strm, C.int(goodLength), C.int(maxLazy), C.int(niceLength), C.int(maxChain)))
+}
+
+// DeflatePrime inserts the low bits of value in the deflate output stream.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflatePrime(bits, value int) ZConstant {
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	return ZConstant(C.DeflatePrime(&z.strm, C.int(bits), C.int(value)))
+}
+
+// DeflatePending returns the number of bytes and bits of output that have been generated
+// but not yet provided in the available output.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) DeflatePending() (int, int, ZConstant) {
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	var pending C.unsigned
+	var bits C.int
+	ret := ZConstant(C.DeflatePending(&z.strm, &pending, &bits))
+	return int(pending), int(bits), ret
+}
+
+// InflatePrime inserts the low bits of value in the inflate input stream.
+// A negative bits empties the input bit buffer.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflatePrime(bits, value int) ZConstant {
+	pinner := runtime.Pinner{}
+	pinner.Pin(&z.strm)
+	defer pinner.Unpin()
+
+	return ZConstant(C.InflatePrime(&z.strm, C.int(bits), C.int(value)))
+}
+
+// InflateSync skips invalid compressed data until a possible full flush point is found
+// or until all the input set by SetInput is skipped.
+// For more details, see http://zlib.net/manual.html#Advanced
+func (z *zstream) InflateSync() ZConstant {
+	return z.wrapOp(OpSync, Z_NO_FLUSH, func() ZConstant {
+		return ZConstant(C.InflateSync(&z.strm))
+	})
+}
+
 // ProducedOutput returns the number of bytes produced in the output buffer.
 func (z *zstream) ProducedOutput() int {
 	return len(z.out) - int(z.strm.avail_out)
@@ -306,19 +635,49 @@ func (z *zstream) AvailIn() int {
 	return int(z.strm.avail_in)
 }
 
+// UnconsumedInput returns the part of the input buffer set by SetInput that is yet to be consumed.
+// It refers to the input buffer and is not copied.
+func (z *zstream) UnconsumedInput() []byte {
+	return z.in[len(z.in)-int(z.strm.avail_in):]
+}
+
+// TotalIn returns the total number of input bytes read so far.
+func (z *zstream) TotalIn() int64 {
+	return int64(z.strm.total_in)
+}
+
+// TotalOut returns the total number of bytes output so far.
+func (z *zstream) TotalOut() int64 {
+	return int64(z.strm.total_out)
+}
+
+// Adler returns the adler field of the stream.
+// It is the Adler-32 or CRC-32 checksum of the uncompressed data so far. With inflate, it is the
+// DICTID of the needed dictionary when Z_NEED_DICT is returned.
+func (z *zstream) Adler() uint32 {
+	return uint32(z.strm.adler)
+}
+
+// DataType returns the data_type field of the stream.
+// After deflate, it is Z_TEXT or Z_BINARY once the first block has been emitted and Z_UNKNOWN before that.
+// After inflate, it holds the number of unused bits in the last byte taken from the input,
+// plus 64 if inflate is decoding the last block, plus 128 if inflate returned right after
+// the end of a block or the end of the header. For more details, see http://zlib.net/manual.html#Basic
+func (z *zstream) DataType() int {
+	return int(z.strm.data_type)
+}
+
 // wrapOp wraps a call to Deflate or Inflate.
 // In order to avoid C pointers having access to free memory in Go memory,
 // next_in and next_out is reset to nil after each call to Deflate or Inflate.
 // They are set again to the correct position in the buffer before each call.
 // The correct position is inferred from the length of the buffer and the
 // value of avail_in and avail_out.
-func (z *zstream) wrapOp(f func()) {
+// The call is logged at debug level after it returns with the flush mode of op.
+func (z *zstream) wrapOp(op Op, flush ZConstant, f func() ZConstant) ZConstant {
 	// Pin buffers
 	pinner := z.pin()
-	defer func() {
-		pinner.Unpin()
-		utils.Debug("wrapOp Unpinned")
-	}()
+	defer pinner.Unpin()
 
 	// Set C pointers
 	if z.strm.avail_in == 0 {
@@ -333,16 +692,47 @@ func (z *zstream) wrapOp(f func()) {
 	}
 
 	// Call f
-	utils.Debug("Before %p next_in:%v, avail_in:%v, next_out:%v, avail_out:%v", &z.strm, z.strm.next_in, z.strm.avail_in, z.strm.next_out, z.strm.avail_out)
-	f()
-	utils.Debug("After %p next_in:%v, avail_in:%v, next_out:%v, avail_out:%v", &z.strm, z.strm.next_in, z.strm.avail_in, z.strm.next_out, z.strm.avail_out)
+	availIn, availOut := int(z.strm.avail_in), int(z.strm.avail_out)
+	ret := f()
 
 	// Reset C pointers
 	z.strm.next_in = nil
 	z.strm.next_out = nil
-	utils.Debug("Cleaned %p next_in:%v, avail_in:%v, next_out:%v, avail_out:%v", &z.strm, z.strm.next_in, z.strm.avail_in, z.strm.next_out, z.strm.avail_out)
+	z.logCall(op, flush, ret, availIn, availOut)
 
 	// Unpin buffers - deferred
+	return ret
+}
+
+// initialized records the state of the stream after a call to one of the init functions.
+func (z *zstream) initialized(ret ZConstant, state zstreamState) ZConstant {
+	if ret == Z_OK {
+		z.state = state
+		z.gzHeaderRead = nil
+		z.gzHeaderDone = 0
+	}
+	return ret
+}
+
+// finalize frees the memory allocated by zlib if the stream is still initialized.
+func (z *zstream) finalize() {
+	switch z.state {
+	case zstreamDeflate:
+		z.DeflateEnd()
+	case zstreamInflate:
+		z.InflateEnd()
+	case zstreamInflateBack:
+		z.InflateBackEnd()
+	}
+}
+
+// freeGzipHeader frees the C memory of the gz_header if any.
+// It must only be called when zlib no longer refers to the gz_header.
+func (z *zstream) freeGzipHeader() {
+	if z.gzHeader != nil {
+		z.gzHeader.free()
+		z.gzHeader = nil
+	}
 }
 
 // pin pins the zstream and its buffers to prevent the GC from moving them.
@@ -0,0 +1,157 @@
+// Package checksum provides the Adler-32 and CRC-32 checksums of zlib as hash.Hash32
+// together with the functions to combine checksums of consecutive sequences.
+//
+// Combining allows computing the checksum of data that is processed in independent chunks,
+// for example when compressing in parallel, without going over the data again.
+package checksum
+
+import (
+	"encoding/binary"
+	"hash"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+)
+
+// Size is the size of Adler-32 and CRC-32 checksums in bytes.
+const Size = 4
+
+// Make sure that Adler32 and CRC32 implement hash.Hash32
+var (
+	_ hash.Hash32 = (*Adler32)(nil)
+	_ hash.Hash32 = (*CRC32)(nil)
+)
+
+// Adler32 computes the Adler-32 checksum used by the zlib format.
+// The zero value is ready to use.
+type Adler32 struct {
+	// sum is the checksum xored with 1 so that the zero value is the checksum of empty data, which is 1.
+	sum uint32
+}
+
+// NewAdler32 returns a new Adler32 with the checksum of empty data.
+func NewAdler32() *Adler32 {
+	return &Adler32{}
+}
+
+// ChecksumAdler32 returns the Adler-32 checksum of data.
+func ChecksumAdler32(data []byte) uint32 {
+	return capi.Adler32(1, data)
+}
+
+// CombineAdler32 returns the Adler-32 checksum of two consecutive sequences
+// given the checksum of each of them and the length of the second one.
+func CombineAdler32(adler1, adler2 uint32, len2 int64) uint32 {
+	return capi.Adler32Combine(adler1, adler2, len2)
+}
+
+// Write adds p to the running checksum. It never returns an error.
+func (d *Adler32) Write(p []byte) (int, error) {
+	d.sum = capi.Adler32(d.Sum32(), p) ^ 1
+	return len(p), nil
+}
+
+// Combine updates the running checksum as if the sequence with checksum adler2 and length len2 had been written.
+func (d *Adler32) Combine(adler2 uint32, len2 int64) {
+	d.sum = capi.Adler32Combine(d.Sum32(), adler2, len2) ^ 1
+}
+
+// Sum32 returns the current checksum.
+func (d *Adler32) Sum32() uint32 {
+	return d.sum ^ 1
+}
+
+// Sum appends the current checksum to b in big-endian order as it is stored in the zlib trailer.
+func (d *Adler32) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint32(b, d.Sum32())
+}
+
+// Reset resets the checksum to the checksum of empty data.
+func (d *Adler32) Reset() {
+	d.sum = 0
+}
+
+// Size returns the size of the checksum in bytes.
+func (d *Adler32) Size() int {
+	return Size
+}
+
+// BlockSize returns the block size of the checksum which is the same as hash/adler32.
+func (d *Adler32) BlockSize() int {
+	return 4
+}
+
+// CRC32 computes the CRC-32 checksum used by the gzip format.
+// The zero value is ready to use.
+type CRC32 struct {
+	sum uint32
+}
+
+// NewCRC32 returns a new CRC32 with the checksum of empty data.
+func NewCRC32() *CRC32 {
+	return &CRC32{}
+}
+
+// ChecksumCRC32 returns the CRC-32 checksum of data.
+func ChecksumCRC32(data []byte) uint32 {
+	return capi.Crc32(0, data)
+}
+
+// CombineCRC32 returns the CRC-32 checksum of two consecutive sequences
+// given the checksum of each of them and the length of the second one.
+func CombineCRC32(crc1, crc2 uint32, len2 int64) uint32 {
+	return capi.Crc32Combine(crc1, crc2, len2)
+}
+
+// Write adds p to the running checksum. It never returns an error.
+func (d *CRC32) Write(p []byte) (int, error) {
+	d.sum = capi.Crc32(d.sum, p)
+	return len(p), nil
+}
+
+// Combine updates the running checksum as if the sequence with checksum crc2 and length len2 had been written.
+func (d *CRC32) Combine(crc2 uint32, len2 int64) {
+	d.sum = capi.Crc32Combine(d.sum, crc2, len2)
+}
+
+// Sum32 returns the current checksum.
+func (d *CRC32) Sum32() uint32 {
+	return d.sum
+}
+
+// Sum appends the current checksum to b in big-endian order like hash/crc32.
+// Note that the gzip trailer stores it in little-endian order.
+func (d *CRC32) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint32(b, d.sum)
+}
+
+// Reset resets the checksum to the checksum of empty data.
+func (d *CRC32) Reset() {
+	d.sum = 0
+}
+
+// Size returns the size of the checksum in bytes.
+func (d *CRC32) Size() int {
+	return Size
+}
+
+// BlockSize returns the block size of the checksum which is the same as hash/crc32.
+func (d *CRC32) BlockSize() int {
+	return 1
+}
+
+// CRC32Combiner combines CRC-32 checksums where the second sequence always has the same length.
+// It is faster than CombineCRC32 when many chunks of the same length are combined.
+type CRC32Combiner struct {
+	op uint32
+}
+
+// NewCRC32Combiner returns a CRC32Combiner for second sequences of length len2.
+func NewCRC32Combiner(len2 int64) CRC32Combiner {
+	return CRC32Combiner{op: capi.Crc32CombineGen(len2)}
+}
+
+// Combine returns the CRC-32 checksum of two consecutive sequences given the checksum of each of them.
+// The second sequence must have the length the combiner was created with.
+func (c CRC32Combiner) Combine(crc1, crc2 uint32) uint32 {
+	return capi.Crc32CombineOp(crc1, crc2, c.op)
+}
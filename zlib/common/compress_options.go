@@ -1,13 +1,21 @@
 package common
 
-import "github.com/MeenaAlfons/go-zlib/zlib/capi"
+import (
+	"context"
+	"log/slog"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+)
 
 type HeaderType int
 
 const (
 	HeaderTypeZlib HeaderType = iota
 	HeaderTypeRaw
-	// HeaderTypeGzip not supported yet.
+	// HeaderTypeGzip wraps the compressed data with a gzip header and trailer instead of a zlib wrapper.
+	HeaderTypeGzip
+	// HeaderTypeAuto accepts both zlib and gzip headers. It is only supported for decompression.
+	HeaderTypeAuto
 )
 
 type StrategyType int
@@ -39,6 +47,11 @@ type CompressOptions interface {
 	Strategy() StrategyType
 	BufferSize() int
 	InitialDictionary() []byte
+	GzipHeader() *GzipHeader
+	Tuning() *Tuning
+	Observer() Observer
+	Logger() *slog.Logger
+	Context() context.Context
 
 	WithLevel(level int) CompressOptions
 	WithWindowBits(windowBits int) CompressOptions
@@ -47,6 +60,26 @@ type CompressOptions interface {
 	WithStrategy(strategy StrategyType) CompressOptions
 	WithBufferSize(bufferSize int) CompressOptions
 	WithInitialDictionary(initialDictionary []byte) CompressOptions
+	// WithGzipHeader sets the gzip header to be written. It is only used with HeaderTypeGzip.
+	// If no gzip header is set, zlib writes a default header without file name, comment nor modification time.
+	WithGzipHeader(gzipHeader *GzipHeader) CompressOptions
+	// WithTuning fine tunes the deflate match finder. nil keeps the values of the compression level.
+	// See Profile for ready to use tunings.
+	WithTuning(tuning *Tuning) CompressOptions
+	// WithObserver sets an observer notified of the events of the stream. nil disables it.
+	WithObserver(observer Observer) CompressOptions
+	// WithLogger sets the logger of the stream. Debug messages are logged for each call to zlib with
+	// the stream pointer, avail_in and avail_out, the flush mode and the return code.
+	// nil uses the global logger set by zlib.SetLogger.
+	WithLogger(logger *slog.Logger) CompressOptions
+	// WithContext sets a context checked before each call to zlib. Once it is done, the stream ends
+	// with the error of the context, for example context.Canceled. nil disables the checks.
+	// The options should not be shared between requests when they hold the context of a request.
+	WithContext(ctx context.Context) CompressOptions
+
+	// Clone returns a copy of the options which can be changed without changing the original.
+	// The tuning is copied. The initial dictionary and the gzip header are shared.
+	Clone() CompressOptions
 }
 
 type compressOptions struct {
@@ -56,6 +89,11 @@ type compressOptions struct {
 	memoryLevel       int
 	strategy          StrategyType
 	initialDictionary []byte
+	gzipHeader        *GzipHeader
+	tuning            *Tuning
+	observer          Observer
+	logger            *slog.Logger
+	ctx               context.Context
 
 	bufferSize int
 }
@@ -88,6 +126,26 @@ func (opts *compressOptions) InitialDictionary() []byte {
 	return opts.initialDictionary
 }
 
+func (opts *compressOptions) GzipHeader() *GzipHeader {
+	return opts.gzipHeader
+}
+
+func (opts *compressOptions) Tuning() *Tuning {
+	return opts.tuning
+}
+
+func (opts *compressOptions) Observer() Observer {
+	return opts.observer
+}
+
+func (opts *compressOptions) Logger() *slog.Logger {
+	return opts.logger
+}
+
+func (opts *compressOptions) Context() context.Context {
+	return opts.ctx
+}
+
 func (opts *compressOptions) WithLevel(level int) CompressOptions {
 	opts.level = level
 	return opts
@@ -122,3 +180,37 @@ func (opts *compressOptions) WithInitialDictionary(initialDictionary []byte) Com
 	opts.initialDictionary = initialDictionary
 	return opts
 }
+
+func (opts *compressOptions) WithGzipHeader(gzipHeader *GzipHeader) CompressOptions {
+	opts.gzipHeader = gzipHeader
+	return opts
+}
+
+func (opts *compressOptions) WithTuning(tuning *Tuning) CompressOptions {
+	opts.tuning = tuning
+	return opts
+}
+
+func (opts *compressOptions) WithObserver(observer Observer) CompressOptions {
+	opts.observer = observer
+	return opts
+}
+
+func (opts *compressOptions) WithLogger(logger *slog.Logger) CompressOptions {
+	opts.logger = logger
+	return opts
+}
+
+func (opts *compressOptions) WithContext(ctx context.Context) CompressOptions {
+	opts.ctx = ctx
+	return opts
+}
+
+func (opts *compressOptions) Clone() CompressOptions {
+	clone := *opts
+	if opts.tuning != nil {
+		tuning := *opts.tuning
+		clone.tuning = &tuning
+	}
+	return &clone
+}
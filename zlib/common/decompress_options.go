@@ -1,5 +1,10 @@
 package common
 
+import (
+	"context"
+	"log/slog"
+)
+
 func DefaultDecompressOptions() DecompressOptions {
 	return &decompressOptions{
 		windowBits: 15,
@@ -13,17 +18,79 @@ type DecompressOptions interface {
 	Header() HeaderType
 	BufferSize() int
 	InitialDictionary() []byte
+	Recovery() RecoveryHandler
+	MultiMember() bool
+	MemberHandler() MemberHandler
+	DictionaryResolver() DictionaryResolver
+	Observer() Observer
+	Logger() *slog.Logger
+	Context() context.Context
+	MaxOutputSize() int64
+	MaxRatio() float64
+	MaxWindowBits() int
 
+	// WithWindowBits sets the base two logarithm of the window size.
+	// It can be set to 0 to use the window size from the zlib header of the compressed stream.
+	// 0 is not supported with HeaderTypeRaw.
 	WithWindowBits(windowBits int) DecompressOptions
+	// WithHeader sets the expected header of the compressed stream.
+	// HeaderTypeAuto accepts both zlib and gzip headers.
 	WithHeader(header HeaderType) DecompressOptions
 	WithBufferSize(bufferSize int) DecompressOptions
 	WithInitialDictionary(initialDictionary []byte) DecompressOptions
+	// WithRecovery enables recovering from corrupted compressed data instead of failing.
+	// On corruption, the compressed data is skipped up to the next full flush point which is where
+	// decompression resumes. Each skipped region is reported to handler. nil disables the recovery.
+	// Only data compressed with periodic full flushes (compression.FullFlush) can be recovered.
+	// The checksum of the stream is not verified after a recovery.
+	WithRecovery(handler RecoveryHandler) DecompressOptions
+	// WithMultiMember enables decompressing back-to-back members as one stream, for example the
+	// output of `cat a.gz b.gz`. Each member has its own header which is checked against WithHeader.
+	// Without it, data after the end of the first member is an error.
+	WithMultiMember(multiMember bool) DecompressOptions
+	// WithMemberHandler sets a handler called at the end of each member. nil disables it.
+	WithMemberHandler(handler MemberHandler) DecompressOptions
+	// WithDictionaryResolver sets a resolver for the dictionary requested by a zlib stream.
+	// It is called with the DICTID of the zlib header when it does not match the initial dictionary.
+	// A DictionaryRegistry can be used with its Resolve method. It has no effect for raw and gzip streams.
+	WithDictionaryResolver(resolver DictionaryResolver) DecompressOptions
+	// WithObserver sets an observer notified of the events of the stream. nil disables it.
+	WithObserver(observer Observer) DecompressOptions
+	// WithLogger sets the logger of the stream. Debug messages are logged for each call to zlib with
+	// the stream pointer, avail_in and avail_out, the flush mode and the return code.
+	// nil uses the global logger set by zlib.SetLogger.
+	WithLogger(logger *slog.Logger) DecompressOptions
+	// WithContext sets a context checked before each call to zlib. Once it is done, the stream ends
+	// with the error of the context, for example context.Canceled. nil disables the checks.
+	// The options should not be shared between requests when they hold the context of a request.
+	WithContext(ctx context.Context) DecompressOptions
+	// WithMaxOutputSize limits the size of the decompressed data, including all members with WithMultiMember.
+	// Decompression fails with a LimitError once more data would be produced. 0 disables the limit.
+	WithMaxOutputSize(maxOutputSize int64) DecompressOptions
+	// WithMaxRatio limits the ratio of the size of the decompressed data to the size of the compressed data consumed so far.
+	// It is checked once the decompressed data exceeds 64 KiB so that small streams of highly compressible data are not rejected.
+	// Decompression fails with a LimitError when the ratio is exceeded. 0 disables the limit.
+	WithMaxRatio(maxRatio float64) DecompressOptions
+	// WithMaxWindowBits limits the window allocated by inflate to 8 to 15 bits. A zlib stream declaring a larger window in its header
+	// is rejected with a LimitError. Raw deflate and gzip streams do not declare their window and fail with ErrData
+	// when they refer further back than the limit. 0 disables the limit.
+	WithMaxWindowBits(maxWindowBits int) DecompressOptions
 }
 
 type decompressOptions struct {
 	windowBits        int
 	header            HeaderType
 	initialDictionary []byte
+	recovery          RecoveryHandler
+	multiMember       bool
+	memberHandler     MemberHandler
+	resolver          DictionaryResolver
+	observer          Observer
+	logger            *slog.Logger
+	ctx               context.Context
+	maxOutputSize     int64
+	maxRatio          float64
+	maxWindowBits     int
 
 	bufferSize int
 }
@@ -44,6 +111,46 @@ func (opts *decompressOptions) InitialDictionary() []byte {
 	return opts.initialDictionary
 }
 
+func (opts *decompressOptions) Recovery() RecoveryHandler {
+	return opts.recovery
+}
+
+func (opts *decompressOptions) MultiMember() bool {
+	return opts.multiMember
+}
+
+func (opts *decompressOptions) MemberHandler() MemberHandler {
+	return opts.memberHandler
+}
+
+func (opts *decompressOptions) DictionaryResolver() DictionaryResolver {
+	return opts.resolver
+}
+
+func (opts *decompressOptions) Observer() Observer {
+	return opts.observer
+}
+
+func (opts *decompressOptions) Logger() *slog.Logger {
+	return opts.logger
+}
+
+func (opts *decompressOptions) Context() context.Context {
+	return opts.ctx
+}
+
+func (opts *decompressOptions) MaxOutputSize() int64 {
+	return opts.maxOutputSize
+}
+
+func (opts *decompressOptions) MaxRatio() float64 {
+	return opts.maxRatio
+}
+
+func (opts *decompressOptions) MaxWindowBits() int {
+	return opts.maxWindowBits
+}
+
 func (opts *decompressOptions) WithWindowBits(windowBits int) DecompressOptions {
 	opts.windowBits = windowBits
 	return opts
@@ -63,3 +170,53 @@ func (opts *decompressOptions) WithInitialDictionary(initialDictionary []byte) D
 	opts.initialDictionary = initialDictionary
 	return opts
 }
+
+func (opts *decompressOptions) WithRecovery(handler RecoveryHandler) DecompressOptions {
+	opts.recovery = handler
+	return opts
+}
+
+func (opts *decompressOptions) WithMultiMember(multiMember bool) DecompressOptions {
+	opts.multiMember = multiMember
+	return opts
+}
+
+func (opts *decompressOptions) WithMemberHandler(handler MemberHandler) DecompressOptions {
+	opts.memberHandler = handler
+	return opts
+}
+
+func (opts *decompressOptions) WithDictionaryResolver(resolver DictionaryResolver) DecompressOptions {
+	opts.resolver = resolver
+	return opts
+}
+
+func (opts *decompressOptions) WithObserver(observer Observer) DecompressOptions {
+	opts.observer = observer
+	return opts
+}
+
+func (opts *decompressOptions) WithLogger(logger *slog.Logger) DecompressOptions {
+	opts.logger = logger
+	return opts
+}
+
+func (opts *decompressOptions) WithContext(ctx context.Context) DecompressOptions {
+	opts.ctx = ctx
+	return opts
+}
+
+func (opts *decompressOptions) WithMaxOutputSize(maxOutputSize int64) DecompressOptions {
+	opts.maxOutputSize = maxOutputSize
+	return opts
+}
+
+func (opts *decompressOptions) WithMaxRatio(maxRatio float64) DecompressOptions {
+	opts.maxRatio = maxRatio
+	return opts
+}
+
+func (opts *decompressOptions) WithMaxWindowBits(maxWindowBits int) DecompressOptions {
+	opts.maxWindowBits = maxWindowBits
+	return opts
+}
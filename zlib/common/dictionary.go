@@ -0,0 +1,66 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+)
+
+// ErrUnknownDictionary is returned by DictionaryRegistry.Resolve when it has no dictionary with the requested id.
+var ErrUnknownDictionary = errors.New("zlib: unknown dictionary")
+
+// DictionaryResolver returns the dictionary with the given id.
+// The id is the Adler-32 checksum of the dictionary which is stored as DICTID in the zlib header.
+type DictionaryResolver func(id uint32) ([]byte, error)
+
+// DictionaryID returns the id of dictionary as stored in the zlib header, which is its Adler-32 checksum.
+func DictionaryID(dictionary []byte) uint32 {
+	return capi.Adler32(1, dictionary)
+}
+
+// DictionaryRegistry holds dictionaries by their id.
+// It is safe for concurrent use so that dictionaries can be added and removed while streams are decompressed.
+type DictionaryRegistry struct {
+	mu           sync.RWMutex
+	dictionaries map[uint32][]byte
+}
+
+// NewDictionaryRegistry creates a DictionaryRegistry holding the given dictionaries.
+func NewDictionaryRegistry(dictionaries ...[]byte) *DictionaryRegistry {
+	r := &DictionaryRegistry{
+		dictionaries: make(map[uint32][]byte, len(dictionaries)),
+	}
+	for _, dictionary := range dictionaries {
+		r.Add(dictionary)
+	}
+	return r
+}
+
+// Add adds dictionary to the registry and returns its id.
+func (r *DictionaryRegistry) Add(dictionary []byte) uint32 {
+	id := DictionaryID(dictionary)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dictionaries[id] = dictionary
+	return id
+}
+
+// Remove removes the dictionary with the given id from the registry.
+func (r *DictionaryRegistry) Remove(id uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.dictionaries, id)
+}
+
+// Resolve returns the dictionary with the given id. It can be used as a DictionaryResolver.
+func (r *DictionaryRegistry) Resolve(id uint32) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dictionary, ok := r.dictionaries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w with id %08x", ErrUnknownDictionary, id)
+	}
+	return dictionary, nil
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Operating system codes of the gzip header as defined by RFC 1952.
+const (
+	GzipOSUnix    byte = 3
+	GzipOSUnknown byte = 255
+)
+
+// GzipHeader holds the metadata stored in a gzip header.
+// It is written when compressing with HeaderTypeGzip and reported when decompressing a gzip stream.
+type GzipHeader struct {
+	// Text indicates that the compressed data is believed to be text.
+	Text bool
+	// ModTime is the modification time. The zero value means that no modification time is available.
+	// It must be between 1970 and 2106 to fit the 32 bits of the gzip header.
+	ModTime time.Time
+	// OS is the operating system code as defined by RFC 1952. For example, GzipOSUnix or GzipOSUnknown.
+	// 0 is written as GzipOSUnknown when compressing, so the code 0 of FAT file systems can not be written.
+	OS byte
+	// Extra is the extra field or nil if none.
+	Extra []byte
+	// Name is the file name or empty if none.
+	Name string
+	// Comment is the comment or empty if none.
+	Comment string
+	// HCRC indicates that the header is (or should be) protected by a header crc.
+	HCRC bool
+}
+
+// EncodedOS returns the operating system code written in the gzip header.
+func (h *GzipHeader) EncodedOS() byte {
+	if h.OS == 0 {
+		return GzipOSUnknown
+	}
+	return h.OS
+}
+
+// EncodedModTime returns the modification time written in the gzip header in seconds since the Unix epoch.
+// It is 0 when ModTime is zero. It returns an error when ModTime does not fit in 32 bits.
+func (h *GzipHeader) EncodedModTime() (uint32, error) {
+	if h.ModTime.IsZero() {
+		return 0, nil
+	}
+	seconds := h.ModTime.Unix()
+	if seconds < 0 || seconds > math.MaxUint32 {
+		return 0, fmt.Errorf("zlib: gzip modification time %v is outside the range of the gzip header", h.ModTime)
+	}
+	return uint32(seconds), nil
+}
+
+// GzipHeaderGetter is implemented by decompressors to report the gzip header read from the stream.
+type GzipHeaderGetter interface {
+	// GzipHeader returns the gzip header once it has been completely read.
+	// It returns false if the header has not been read yet or if the stream has no gzip header.
+	GzipHeader() (*GzipHeader, bool)
+}
+
+// HeaderDetector is implemented by decompressors to report the header type found in the stream.
+type HeaderDetector interface {
+	// DetectedHeader returns the header type of the stream.
+	// With HeaderTypeAuto, it returns false until enough of the stream has been read to detect the header.
+	DetectedHeader() (HeaderType, bool)
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrLimitExceeded is matched with errors.Is by the LimitError returned when decompression exceeds
+// a limit set by WithMaxOutputSize, WithMaxRatio or WithMaxWindowBits.
+var ErrLimitExceeded = errors.New("zlib: limit exceeded")
+
+// Limit is a limit of DecompressOptions.
+type Limit string
+
+const (
+	LimitOutputSize Limit = "output size"
+	LimitRatio      Limit = "ratio"
+	LimitWindowBits Limit = "window bits"
+)
+
+// LimitError is returned when decompression exceeds a limit of DecompressOptions.
+// The stream ends and can not be used anymore.
+type LimitError struct {
+	Limit Limit
+	// Value is the value that exceeded the limit.
+	// For the output size, it is the size at which decompression stopped, one byte past the limit.
+	Value float64
+	// Max is the limit that was set in the options.
+	Max float64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("zlib: decompression exceeds the limit of %s for the %s with %s", formatLimit(e.Max), e.Limit, formatLimit(e.Value))
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+func formatLimit(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
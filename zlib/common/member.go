@@ -0,0 +1,20 @@
+package common
+
+// Member describes one member of a compressed stream made of back-to-back zlib, gzip or raw deflate streams.
+type Member struct {
+	// Header is the header of the member.
+	Header HeaderType
+	// GzipHeader is the gzip header of the member or nil if the member has no gzip header.
+	GzipHeader *GzipHeader
+	// Checksum is the Adler-32 checksum for zlib or the CRC-32 checksum for gzip of the uncompressed data.
+	// It is zero for raw deflate.
+	Checksum uint32
+	// CompressedSize is the size of the member in the compressed stream including its header and trailer.
+	CompressedSize int64
+	// UncompressedSize is the size of the uncompressed data of the member.
+	UncompressedSize int64
+}
+
+// MemberHandler is called at the end of each member of a compressed stream.
+// It is called from within Read, Write, Flush or Close of the decompress reader or writer.
+type MemberHandler func(member Member)
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+)
+
+// Direction tells whether a stream compresses or decompresses.
+type Direction int
+
+const (
+	DirectionCompress Direction = iota
+	DirectionDecompress
+)
+
+func (d Direction) String() string {
+	if d == DirectionCompress {
+		return "compress"
+	}
+	return "decompress"
+}
+
+// Call describes a call to Feed or Consume.
+type Call struct {
+	Direction Direction
+	// BytesIn is the number of bytes of input consumed by zlib during the call.
+	BytesIn int
+	// BytesOut is the number of bytes of output produced by zlib during the call.
+	BytesOut int
+	// Duration is the time spent in zlib during the call.
+	Duration time.Duration
+}
+
+// Observer is notified of the events of the streams it is set on with WithObserver.
+// It is meant for metrics and tracing. Its methods are called synchronously from the stream,
+// so they should be fast, and they must be safe for concurrent use when the Observer is shared by several streams.
+// NopObserver can be embedded to implement only some of the methods.
+type Observer interface {
+	// StreamInit is called when a stream is initialized or reset.
+	StreamInit(direction Direction)
+	// ZlibCall is called right before calling into zlib with op. The returned function, if not nil,
+	// is called right after the call returns. It can be used to annotate the calls into zlib when tracing.
+	ZlibCall(direction Direction, op capi.Op) func()
+	// Feed is called after each call to Feed that calls into zlib.
+	Feed(call Call)
+	// Consume is called after each call to Consume that calls into zlib.
+	Consume(call Call)
+	// Flush is called when input is fed with a flush other than NoFlush, including the final one.
+	Flush(direction Direction)
+	// StreamEnd is called when a stream ends successfully with the final statistics of the stream.
+	StreamEnd(direction Direction, stats Stats)
+	// Error is called when a stream ends because of an error.
+	Error(direction Direction, err error)
+}
+
+// NopObserver is an Observer that does nothing.
+type NopObserver struct{}
+
+// Make sure that NopObserver implements Observer
+var _ Observer = NopObserver{}
+
+func (NopObserver) StreamInit(Direction)               {}
+func (NopObserver) ZlibCall(Direction, capi.Op) func() { return nil }
+func (NopObserver) Feed(Call)                          {}
+func (NopObserver) Consume(Call)                       {}
+func (NopObserver) Flush(Direction)                    {}
+func (NopObserver) StreamEnd(Direction, Stats)         {}
+func (NopObserver) Error(Direction, error)             {}
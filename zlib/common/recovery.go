@@ -0,0 +1,17 @@
+package common
+
+// SkippedRegion describes compressed data that was skipped to recover from corruption.
+type SkippedRegion struct {
+	// Offset is the number of compressed bytes consumed when the corruption was detected.
+	// inflate may detect the corruption a few bytes after the actual corrupted bytes.
+	Offset int64
+	// Length is the number of compressed bytes skipped starting at Offset, including the full flush marker.
+	// The decompressed data contained in these bytes is lost.
+	Length int64
+	// Err is the error that triggered the recovery.
+	Err error
+}
+
+// RecoveryHandler is called with each region skipped by the recovery mode.
+// It is called from within Read, Write, Flush or Close of the decompress reader or writer.
+type RecoveryHandler func(region SkippedRegion)
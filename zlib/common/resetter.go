@@ -0,0 +1,27 @@
+package common
+
+import "io"
+
+// CompressReaderResetter is implemented by the ReadCloser returned by NewCompressReader.
+type CompressReaderResetter interface {
+	// Reset discards the current state and makes the reader compress data read from target with the given options.
+	Reset(target io.Reader, opts CompressOptions) error
+}
+
+// CompressWriterResetter is implemented by the WriteFlushCloser returned by NewCompressWriter.
+type CompressWriterResetter interface {
+	// Reset discards the current state and makes the writer write compressed data to target with the given options.
+	Reset(target io.Writer, opts CompressOptions) error
+}
+
+// DecompressReaderResetter is implemented by the ReadCloser returned by NewDecompressReader.
+type DecompressReaderResetter interface {
+	// Reset discards the current state and makes the reader decompress data read from target with the given options.
+	Reset(target io.Reader, opts DecompressOptions) error
+}
+
+// DecompressWriterResetter is implemented by the WriteFlushCloser returned by NewDecompressWriter.
+type DecompressWriterResetter interface {
+	// Reset discards the current state and makes the writer write decompressed data to target with the given options.
+	Reset(target io.Writer, opts DecompressOptions) error
+}
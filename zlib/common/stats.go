@@ -0,0 +1,42 @@
+package common
+
+import "github.com/MeenaAlfons/go-zlib/zlib/capi"
+
+// DataType is the type of the uncompressed data guessed by deflate.
+type DataType int
+
+const (
+	// DataTypeUnknown is reported before deflate emits the first block and for decompression unless the gzip header marks the data as text.
+	DataTypeUnknown DataType = DataType(capi.Z_UNKNOWN)
+	DataTypeBinary  DataType = DataType(capi.Z_BINARY)
+	DataTypeText    DataType = DataType(capi.Z_TEXT)
+)
+
+// Stats reports the progress of a compression or decompression stream.
+type Stats struct {
+	// TotalIn is the number of bytes consumed by zlib so far.
+	TotalIn int64
+	// TotalOut is the number of bytes produced by zlib so far.
+	TotalOut int64
+	// Ratio is the size of the uncompressed data divided by the size of the compressed data so far.
+	// It is 0 before any compressed data is produced or consumed.
+	Ratio float64
+	// Checksum is the running Adler-32 checksum for zlib or CRC-32 checksum for gzip of the uncompressed data.
+	// It is not computed for raw deflate. For a multi-member stream, it is the checksum of the current member.
+	Checksum uint32
+	// PendingBytes and PendingBits are the compressed output held inside zlib which has not been produced yet.
+	// They are always zero for decompression.
+	PendingBytes int
+	PendingBits  int
+	// DataType is whether deflate guessed that the uncompressed data is text or binary.
+	// For decompression, it is DataTypeText when the gzip header marks the data as text and DataTypeUnknown otherwise.
+	DataType DataType
+	// Flushes is the number of times input has been fed with a flush other than NoFlush, including the final one.
+	Flushes int64
+}
+
+// StatsReporter is implemented by the compress and decompress readers and writers.
+type StatsReporter interface {
+	// Stats returns the statistics of the current stream.
+	Stats() Stats
+}
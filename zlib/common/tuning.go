@@ -0,0 +1,100 @@
+package common
+
+// Tuning holds the internal parameters of the deflate match finder.
+// It should only be used by someone who understands the algorithm used by zlib's deflate for searching
+// for the longest matching string. For more details, see deflateTune() in http://zlib.net/manual.html#Advanced
+// and configuration_table in zlib's deflate.c.
+type Tuning struct {
+	// GoodLength reduces the lazy search above this match length.
+	GoodLength int
+	// MaxLazy does not perform lazy search above this match length.
+	// With levels 1 to 3, it is the maximum length of a match that is inserted in the hash table.
+	MaxLazy int
+	// NiceLength quits the search above this match length.
+	NiceLength int
+	// MaxChain is the maximum number of hash chain entries to search.
+	MaxChain int
+}
+
+// Profile is a named set of compression options tuned for a kind of workload.
+type Profile struct {
+	Name        string
+	Level       int
+	MemoryLevel int
+	Strategy    StrategyType
+	Tuning      *Tuning
+}
+
+// Apply sets the level, memory level, strategy and tuning of the profile on opts.
+// The tuning is copied so that changing it in opts does not change the profile.
+func (p Profile) Apply(opts CompressOptions) CompressOptions {
+	var tuning *Tuning
+	if p.Tuning != nil {
+		t := *p.Tuning
+		tuning = &t
+	}
+	return opts.
+		WithLevel(p.Level).
+		WithMemoryLevel(p.MemoryLevel).
+		WithStrategy(p.Strategy).
+		WithTuning(tuning)
+}
+
+var (
+	// ProfileFastest favors speed over ratio by searching very short hash chains.
+	ProfileFastest = Profile{
+		Name:        "fastest",
+		Level:       1,
+		MemoryLevel: 8,
+		Strategy:    StrategyDefault,
+		Tuning:      &Tuning{GoodLength: 4, MaxLazy: 4, NiceLength: 8, MaxChain: 2},
+	}
+
+	// ProfileBalanced matches zlib's default level 6.
+	ProfileBalanced = Profile{
+		Name:        "balanced",
+		Level:       6,
+		MemoryLevel: 8,
+		Strategy:    StrategyDefault,
+		Tuning:      &Tuning{GoodLength: 8, MaxLazy: 16, NiceLength: 128, MaxChain: 128},
+	}
+
+	// ProfileMaxRatio searches longer hash chains than level 9 and never shortens the lazy search.
+	// It is considerably slower than level 9 for a small gain in ratio.
+	ProfileMaxRatio = Profile{
+		Name:        "max-ratio",
+		Level:       9,
+		MemoryLevel: 9,
+		Strategy:    StrategyDefault,
+		Tuning:      &Tuning{GoodLength: 258, MaxLazy: 258, NiceLength: 258, MaxChain: 1 << 14},
+	}
+
+	// ProfilePNG is meant for data produced by a filter or predictor such as PNG image rows.
+	// Such data consists mostly of small values with a somewhat random distribution.
+	ProfilePNG = Profile{
+		Name:        "png",
+		Level:       9,
+		MemoryLevel: 9,
+		Strategy:    StrategyFiltered,
+		Tuning:      &Tuning{GoodLength: 32, MaxLazy: 128, NiceLength: 258, MaxChain: 1024},
+	}
+
+	// ProfileTelemetry is meant for telemetry with long runs of repeated values and records that repeat
+	// at short distances. It accepts the first long match and keeps the hash chain search short.
+	ProfileTelemetry = Profile{
+		Name:        "telemetry",
+		Level:       5,
+		MemoryLevel: 8,
+		Strategy:    StrategyDefault,
+		Tuning:      &Tuning{GoodLength: 4, MaxLazy: 16, NiceLength: 258, MaxChain: 16},
+	}
+)
+
+// Profiles lists the named profiles shipped with this library.
+var Profiles = []Profile{
+	ProfileFastest,
+	ProfileBalanced,
+	ProfileMaxRatio,
+	ProfilePNG,
+	ProfileTelemetry,
+}
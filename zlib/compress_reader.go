@@ -10,6 +10,10 @@ import (
 
 // NewCompressReader reads uncompressed data from target and compresses it.
 // It returns a ReadCloser that reads compressed data.
+// The returned value implements common.CompressReaderResetter to be reused for another stream.
+// It also implements Primer, PendingReporter, DictionaryGetter and common.StatsReporter.
+// Close must be called once done, also after the end of the stream, to release the memory allocated by zlib.
+// Otherwise, it is only released when the value is garbage collected.
 func NewCompressReader(target io.Reader, opts common.CompressOptions) (io.ReadCloser, error) {
 	zcompressor, err := compression.NewCompressor(opts)
 	if err != nil {
@@ -17,13 +21,15 @@ func NewCompressReader(target io.Reader, opts common.CompressOptions) (io.ReadCl
 	}
 
 	r := &compressReader{
-		impl: feederio.NewFeederReader(target, zcompressor, opts.BufferSize()),
+		impl:       feederio.NewFeederReader(target, zcompressor, opts.BufferSize()),
+		compressor: zcompressor,
 	}
 	return r, nil
 }
 
 type compressReader struct {
-	impl io.ReadCloser
+	impl       feederio.FeederReader
+	compressor compression.Compressor
 }
 
 // Read reads compressed data resulting from compressing the data read from target.
@@ -31,7 +37,46 @@ func (r *compressReader) Read(p []byte) (n int, err error) {
 	return r.impl.Read(p)
 }
 
-// Close method is unnecessary at this moment.
+// Close releases the zlib state. A Reset after Close needs to initialize it again.
+// If Close is not called, the zlib state is released when the reader is garbage collected.
 func (r *compressReader) Close() error {
-	return r.impl.Close()
+	err := r.impl.Close()
+	closeErr := r.compressor.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Reset discards the current state and makes the reader compress data read from target with the given options.
+// The buffers are reused when the buffer size did not change.
+// The zlib state is reused when Close has not been called and the window bits and memory level did not change.
+func (r *compressReader) Reset(target io.Reader, opts common.CompressOptions) error {
+	err := r.compressor.Reset(opts)
+	if err != nil {
+		return err
+	}
+	r.impl.Reset(target, opts.BufferSize())
+	return nil
+}
+
+// Prime inserts the low bits of value at the start of the compressed output.
+// It can only be used with common.HeaderTypeRaw and before the first Read.
+func (r *compressReader) Prime(bits, value int) error {
+	return r.compressor.Prime(bits, value)
+}
+
+// Pending returns the number of bytes and bits of compressed output that are buffered inside zlib.
+func (r *compressReader) Pending() (int, int, error) {
+	return r.compressor.Pending()
+}
+
+// GetDictionary returns up to the last 32 KiB of data read from target so far.
+func (r *compressReader) GetDictionary() ([]byte, error) {
+	return r.compressor.GetDictionary()
+}
+
+// Stats returns the statistics of the current stream.
+func (r *compressReader) Stats() common.Stats {
+	return r.compressor.Stats()
 }
@@ -10,6 +10,14 @@ import (
 
 // NewCompressWriter writes compressed data to target.
 // It returns a WriteFlushCloser which is used to write decompressed data to be compressed.
+// The returned value implements common.CompressWriterResetter to be reused for another stream.
+// It also implements ModeFlusher to flush with a mode other than compression.SyncFlush
+// and ParamsSetter to change the level and strategy in the middle of the stream.
+// Primer and PendingReporter are implemented to append to an existing raw deflate bitstream.
+// DictionaryGetter returns the data written recently, for example to be used as the dictionary of the next stream.
+// common.StatsReporter reports the sizes, checksum and pending output of the stream.
+// Close must be called once done, also after the end of the stream, to release the memory allocated by zlib.
+// Otherwise, it is only released when the value is garbage collected.
 func NewCompressWriter(target io.Writer, opts common.CompressOptions) (common.WriteFlushCloser, error) {
 	zcompressor, err := compression.NewCompressor(opts)
 	if err != nil {
@@ -17,13 +25,47 @@ func NewCompressWriter(target io.Writer, opts common.CompressOptions) (common.Wr
 	}
 
 	r := &compressWriter{
-		impl: feederio.NewFeederWriter(target, zcompressor, opts.BufferSize()),
+		impl:       feederio.NewFeederWriter(target, zcompressor, opts.BufferSize()),
+		compressor: zcompressor,
 	}
 	return r, nil
 }
 
+// ModeFlusher is implemented by the WriteFlushCloser returned by NewCompressWriter.
+type ModeFlusher interface {
+	// FlushWith flushes the compressed data so far using the given flush mode.
+	FlushWith(mode compression.Flush) error
+}
+
+// ParamsSetter is implemented by the WriteFlushCloser returned by NewCompressWriter.
+type ParamsSetter interface {
+	// SetParams changes the compression level and strategy for the data written after this call.
+	SetParams(level int, strategy common.StrategyType) error
+}
+
+// Primer is implemented by the compress and decompress writers and readers.
+type Primer interface {
+	// Prime inserts the low bits of value at the start of a raw deflate stream. bits must be at most 16.
+	// For compression, the bits are inserted in the output. For decompression, they are inserted in front of the input.
+	// It must be called before the first Write or Read.
+	Prime(bits, value int) error
+}
+
+// DictionaryGetter is implemented by the compress and decompress writers and readers.
+type DictionaryGetter interface {
+	// GetDictionary returns the sliding window of zlib which holds up to the last 32 KiB of decompressed data.
+	GetDictionary() ([]byte, error)
+}
+
+// PendingReporter is implemented by the compress writer and reader.
+type PendingReporter interface {
+	// Pending returns the number of bytes and bits of compressed output that are buffered inside zlib.
+	Pending() (pendingBytes int, pendingBits int, err error)
+}
+
 type compressWriter struct {
-	impl common.WriteFlushCloser
+	impl       feederio.FeederWriter
+	compressor compression.Compressor
 }
 
 // Write writes decompressed data which will be compressed and written to target.
@@ -36,8 +78,65 @@ func (w *compressWriter) Flush() error {
 	return w.impl.Flush()
 }
 
+// FlushWith flushes the compressed data so far to target using the given flush mode.
+// compression.FullFlush additionally resets the compression state so that decompression can restart from this point.
+// compression.PartialFlush and compression.Block flush without aligning the output on a byte boundary.
+func (w *compressWriter) FlushWith(mode compression.Flush) error {
+	return w.impl.FlushWith(mode)
+}
+
+// SetParams changes the compression level and strategy for the data written after this call.
+// The data written so far is flushed with compression.Block so that it is compressed with the previous parameters.
+func (w *compressWriter) SetParams(level int, strategy common.StrategyType) error {
+	err := w.impl.FlushWith(compression.Block)
+	if err != nil {
+		return err
+	}
+	return w.compressor.SetParams(level, strategy)
+}
+
 // Close method concludes the compression process and flushes the remaining compressed data to target.
 // Write and Flush methods can not be called after Close.
+// Close releases the zlib state. A Reset after Close needs to initialize it again.
 func (w *compressWriter) Close() error {
-	return w.impl.Close()
+	err := w.impl.Close()
+	closeErr := w.compressor.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Reset discards the current state and makes the writer write compressed data to target with the given options.
+// The buffers are reused when the buffer size did not change.
+// The zlib state is reused when Close has not been called and the window bits and memory level did not change.
+func (w *compressWriter) Reset(target io.Writer, opts common.CompressOptions) error {
+	err := w.compressor.Reset(opts)
+	if err != nil {
+		return err
+	}
+	w.impl.Reset(target, opts.BufferSize())
+	return nil
+}
+
+// Prime inserts the low bits of value at the start of the compressed output.
+// It can only be used with common.HeaderTypeRaw and before the first Write.
+func (w *compressWriter) Prime(bits, value int) error {
+	return w.compressor.Prime(bits, value)
+}
+
+// Pending returns the number of bytes and bits of compressed output that are buffered inside zlib.
+// The data written but not yet fed to zlib is not included.
+func (w *compressWriter) Pending() (int, int, error) {
+	return w.compressor.Pending()
+}
+
+// GetDictionary returns up to the last 32 KiB of data written so far.
+func (w *compressWriter) GetDictionary() ([]byte, error) {
+	return w.compressor.GetDictionary()
+}
+
+// Stats returns the statistics of the current stream.
+func (w *compressWriter) Stats() common.Stats {
+	return w.compressor.Stats()
 }
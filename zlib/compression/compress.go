@@ -1,41 +1,76 @@
 package compression
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 
 	"github.com/MeenaAlfons/go-zlib/zlib/capi"
 	"github.com/MeenaAlfons/go-zlib/zlib/common"
-	"github.com/MeenaAlfons/go-zlib/zlib/utils"
 )
 
 // NewCompressor creates a new compressor FeederConsumer with the given options.
-func NewCompressor(opts common.CompressOptions) (FeederConsumer, error) {
+// HeaderTypeAuto is rejected since it is only supported for decompression.
+// The zlib state is kept after the end of the stream so that it can be reused by Reset. Close must be called to release it.
+func NewCompressor(opts common.CompressOptions) (Compressor, error) {
 	c := &compressor{
-		zstream: capi.NewZStream(),
+		zstream:  capi.NewZStream(),
+		released: true,
 	}
 
-	ret := c.zstream.DeflateInit2(opts.Level(), zWindowBits(opts), opts.MemoryLevel(), int(opts.Strategy()))
-	if ret != capi.Z_OK {
-		return nil, capi.ZError(ret)
+	err := c.Reset(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	if opts.InitialDictionary() != nil {
-		ret = c.zstream.DeflateSetDictionary(opts.InitialDictionary())
-		if ret != capi.Z_OK {
-			return nil, capi.ZError(ret)
-		}
-	}
+	return newCompressorSafeOutputBuffer(c), nil
+}
 
-	return newFeederConsumerSafeOutputBuffer(c), nil
+// compressParams are the options used to initialize the stream.
+// deflateReset can only be used when windowBits and memoryLevel do not change.
+// level and strategy can be changed by deflateParams.
+type compressParams struct {
+	level       int
+	windowBits  int
+	memoryLevel int
+	strategy    common.StrategyType
+}
+
+func newCompressParams(opts common.CompressOptions) compressParams {
+	return compressParams{
+		level:       opts.Level(),
+		windowBits:  zWindowBits(opts),
+		memoryLevel: opts.MemoryLevel(),
+		strategy:    opts.Strategy(),
+	}
 }
 
 type compressor struct {
 	zstream capi.ZStream
+	params  compressParams
+
+	// released is true when the zlib state is not allocated.
+	// This is the case before initialization, after an unrecoverable error and after Close.
+	released bool
 
 	lastFlush     Flush
 	hasMoreOutput bool
 
+	// hasUnflushedInput is true when input has been fed with NoFlush since the last flush.
+	hasUnflushedInput bool
+
+	// fed is true when Feed has been called since the stream started.
+	fed bool
+	// flushes is the number of calls to Feed with a flush other than NoFlush since the stream started.
+	flushes int64
+	// observation reports the events of the stream to the observer of the options.
+	observation observation
+	// logger is the logger of the options. nil uses the global logger.
+	logger *slog.Logger
+	// ctx is the context of the options checked before each call to zlib. It can be nil.
+	ctx context.Context
+
 	// StreamEnd is called when the stream has successfully ended or when an unrecoverable error has occurred
 	streamEndHasBeenCalled bool
 
@@ -48,6 +83,227 @@ type compressor struct {
 	streamEndReason error
 }
 
+// Reset discards the current state and starts a new stream with the given options.
+// deflateReset is used when the zlib state is still allocated and the options that can only be set
+// at initialization did not change. Otherwise, the zlib state is initialized again.
+func (c *compressor) Reset(opts common.CompressOptions) error {
+	c.observation = observation{observer: opts.Observer(), direction: common.DirectionCompress}
+	c.logger = opts.Logger()
+	c.ctx = opts.Context()
+	c.zstream.SetLogger(c.logger)
+	params := newCompressParams(opts)
+	if !c.released && c.params.windowBits == params.windowBits && c.params.memoryLevel == params.memoryLevel {
+		ret := c.zstream.DeflateReset()
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpReset, ret))
+		}
+		if c.params != params {
+			// deflateParams does not need to compress anything right after deflateReset.
+			ret = c.zstream.DeflateParams(params.level, int(params.strategy))
+			if ret != capi.Z_OK {
+				return c.endStream(c.zstream.Error(capi.OpParams, ret))
+			}
+			c.params = params
+		}
+	} else {
+		if !c.released {
+			// deflateEnd returns Z_DATA_ERROR if the stream did not end which is expected here.
+			c.zstream.DeflateEnd()
+			c.released = true
+		}
+		// HeaderTypeAuto always needs a new initialization since its window bits differ from those of the other headers.
+		if opts.Header() == common.HeaderTypeAuto {
+			return c.initFailed(errAutoHeaderCompression)
+		}
+		ret := c.zstream.DeflateInit2(params.level, params.windowBits, params.memoryLevel, int(params.strategy))
+		if ret != capi.Z_OK {
+			return c.initFailed(c.zstream.Error(capi.OpInit, ret))
+		}
+		c.released = false
+		c.params = params
+	}
+
+	c.lastFlush = NoFlush
+	c.hasMoreOutput = false
+	c.hasUnflushedInput = false
+	c.fed = false
+	c.flushes = 0
+	c.streamEndHasBeenCalled = false
+	c.streamEndError = nil
+	c.streamEndReason = nil
+	c.observation.streamInit()
+
+	if tuning := opts.Tuning(); tuning != nil {
+		ret := c.zstream.DeflateTune(tuning.GoodLength, tuning.MaxLazy, tuning.NiceLength, tuning.MaxChain)
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpTune, ret))
+		}
+	}
+
+	if opts.InitialDictionary() != nil {
+		ret := c.zstream.DeflateSetDictionary(opts.InitialDictionary())
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpSetDictionary, ret))
+		}
+	}
+
+	if opts.Header() == common.HeaderTypeGzip && opts.GzipHeader() != nil {
+		zheader, err := toZGzipHeader(opts.GzipHeader())
+		if err != nil {
+			return c.endStream(err)
+		}
+		ret := c.zstream.DeflateSetHeader(zheader)
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpSetHeader, ret))
+		}
+	}
+
+	return nil
+}
+
+// ClearOptions drops the context, observer and logger of the options while keeping the zlib state.
+func (c *compressor) ClearOptions() {
+	c.observation = observation{direction: common.DirectionCompress}
+	c.logger = nil
+	c.ctx = nil
+	c.zstream.SetLogger(nil)
+}
+
+// initFailed ends the stream with reason when the zlib state could not be initialized.
+func (c *compressor) initFailed(reason error) error {
+	c.streamEndHasBeenCalled = true
+	c.streamEndReason = reason
+	c.streamEndError = reason
+	c.observation.streamEnd(reason, nil)
+	return reason
+}
+
+// SetParams changes the compression level and strategy for the input fed after this call.
+// Changing the level replaces the tuning with the values of the new level.
+// The input fed so far must have been flushed with any flush other than NoFlush, for example Block,
+// so that it is compressed with the previous parameters. Otherwise, an error is returned and the
+// parameters are not changed. The stream is still usable in that case.
+// SetParams can not be called when there is output to be consumed.
+func (c *compressor) SetParams(level int, strategy common.StrategyType) error {
+	if c.streamEndHasBeenCalled {
+		return fmt.Errorf("zlib: stream has ended and cannot be used anymore. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+
+	if c.CanCallConsume() {
+		return fmt.Errorf("zlib: cannot call SetParams when there is still output to be consumed. Call Consume instead. Always check CanCallConsume")
+	}
+
+	if c.hasUnflushedInput {
+		return fmt.Errorf("zlib: the input fed so far must be flushed before changing the parameters")
+	}
+
+	// deflateParams calls deflate with Z_BLOCK which requires an output buffer.
+	// Since the input has been flushed and the output has been consumed, deflate has nothing to write.
+	// A scratch buffer with one extra byte of capacity is provided for memory safety reasons.
+	scratch := make([]byte, 2)
+	c.zstream.SetInput(nil)
+	c.zstream.SetOutput(scratch[:1])
+	ret := c.zstream.DeflateParams(level, int(strategy))
+	c.zstream.SetOutput(nil)
+	switch ret {
+	case capi.Z_OK:
+		c.params.level = level
+		c.params.strategy = strategy
+		return nil
+	case capi.Z_BUF_ERROR:
+		return fmt.Errorf("zlib: the input fed so far must be flushed before changing the parameters: %w", c.zstream.Error(capi.OpParams, ret))
+	default:
+		// Z_STREAM_ERROR indicates invalid parameters or an inconsistent stream state.
+		return c.zstream.Error(capi.OpParams, ret)
+	}
+}
+
+// Prime inserts the low bits of value at the start of the deflate output.
+// It is used to continue a raw deflate bitstream that ends on a non-byte boundary.
+// It can only be used for raw deflate and before the first call to Feed.
+// It can be called multiple times to insert more than 16 bits.
+func (c *compressor) Prime(bits, value int) error {
+	if c.streamEndHasBeenCalled {
+		return fmt.Errorf("zlib: stream has ended and cannot be used anymore. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+
+	if c.params.windowBits >= 0 {
+		return fmt.Errorf("zlib: Prime can only be used with raw deflate")
+	}
+
+	if c.fed {
+		return fmt.Errorf("zlib: Prime can only be called before the first call to Feed")
+	}
+
+	ret := c.zstream.DeflatePrime(bits, value)
+	if ret != capi.Z_OK {
+		// Z_BUF_ERROR indicates that there is not enough room for the bits and Z_STREAM_ERROR that bits is invalid.
+		// The stream is still usable in both cases.
+		return c.zstream.Error(capi.OpPrime, ret)
+	}
+	return nil
+}
+
+// Pending returns the number of bytes and bits of compressed output that have been generated
+// but not yet produced by Feed or Consume. The bits are at most 7 and follow the bytes.
+func (c *compressor) Pending() (int, int, error) {
+	if c.released {
+		return 0, 0, fmt.Errorf("zlib: stream has been released. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+
+	pendingBytes, pendingBits, ret := c.zstream.DeflatePending()
+	if ret != capi.Z_OK {
+		return 0, 0, c.zstream.Error(capi.OpPending, ret)
+	}
+	return pendingBytes, pendingBits, nil
+}
+
+// Bound returns an upper bound on the size of the output for sourceLength bytes of input fed in a single call with Finish.
+// The bound accounts for the options the stream was reset with, including the gzip header.
+func (c *compressor) Bound(sourceLength int) (int, error) {
+	if c.released {
+		return 0, fmt.Errorf("zlib: stream has been released. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+	return c.zstream.DeflateBound(sourceLength), nil
+}
+
+// GetDictionary returns the sliding window of deflate which holds up to the last 32 KiB of input.
+// It includes the input that has been fed but not compressed yet.
+// It can be used as the initial dictionary of the next stream or as a restart point.
+func (c *compressor) GetDictionary() ([]byte, error) {
+	if c.released {
+		return nil, fmt.Errorf("zlib: stream has been released. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+
+	dictionary, ret := c.zstream.DeflateGetDictionary()
+	if ret != capi.Z_OK {
+		return nil, c.zstream.Error(capi.OpGetDictionary, ret)
+	}
+	return dictionary, nil
+}
+
+// Stats returns the statistics of the current stream.
+// The pending output is only reported while the zlib state is allocated.
+func (c *compressor) Stats() common.Stats {
+	stats := common.Stats{
+		TotalIn:  c.zstream.TotalIn(),
+		TotalOut: c.zstream.TotalOut(),
+		DataType: common.DataType(c.zstream.DataType()),
+		Flushes:  c.flushes,
+	}
+	if stats.TotalOut > 0 {
+		stats.Ratio = float64(stats.TotalIn) / float64(stats.TotalOut)
+	}
+	// deflate does not compute a checksum for raw deflate.
+	if c.params.windowBits >= 0 {
+		stats.Checksum = c.zstream.Adler()
+	}
+	if !c.released {
+		stats.PendingBytes, stats.PendingBits, _ = c.zstream.DeflatePending()
+	}
+	return stats
+}
+
 // Make sure that the input buffer has capacity larger than its size by at least one.
 // This is to avoid the case where the stream ends at the end of the buffer which would
 // result in an internal state that points past the end of the buffer and causes an error
@@ -67,15 +323,31 @@ func (c *compressor) Feed(input []byte, flush Flush, outputBuffer []byte) (int,
 		return 0, fmt.Errorf("zlib: cannot call Feed when there is still output to be consumed. Call Consume instead. Always check CanCallConsume")
 	}
 
+	if flush == Trees {
+		return 0, fmt.Errorf("zlib: flush = Trees is not supported for compression")
+	}
+
+	if err := contextErr(c.ctx); err != nil {
+		return 0, c.endStream(err)
+	}
+
 	c.lastFlush = flush
+	c.fed = true
+	if flush != NoFlush {
+		c.flushes++
+		c.observation.flush()
+	}
+	c.hasUnflushedInput = flush == NoFlush && (c.hasUnflushedInput || len(input) > 0)
 	zflush := zFlush(c.lastFlush)
 	c.zstream.SetInput(input)
 	c.zstream.SetOutput(outputBuffer)
+	zcall := c.observation.begin(c.zstream, capi.OpDeflate)
 	ret := c.zstream.Deflate(zflush)
+	c.observation.feed(c.observation.end(c.zstream, zcall))
 	have := c.zstream.ProducedOutput()
 	c.hasMoreOutput = c.zstream.OutputBufferIsFull()
 	err := c.processReturnValue(ret)
-	utils.Debug("ZCompress Feed flush:%v have:%v err:%v hasMoreOutput:%v len(input):%v len(output):%v, output:%x", c.lastFlush, have, err, c.hasMoreOutput, len(input), len(outputBuffer), outputBuffer[:have])
+	logCall(c.logger, "compress feed", c.zstream, c.lastFlush, len(input), have, c.hasMoreOutput, err)
 	return have, err
 }
 
@@ -106,13 +378,19 @@ func (c *compressor) Consume(outputBuffer []byte) (int, error) {
 		return 0, nil
 	}
 
+	if err := contextErr(c.ctx); err != nil {
+		return 0, c.endStream(err)
+	}
+
 	zflush := zFlush(c.lastFlush)
 	c.zstream.SetOutput(outputBuffer)
+	zcall := c.observation.begin(c.zstream, capi.OpDeflate)
 	ret := c.zstream.Deflate(zflush)
+	c.observation.consume(c.observation.end(c.zstream, zcall))
 	have := c.zstream.ProducedOutput()
 	c.hasMoreOutput = c.zstream.OutputBufferIsFull()
 	err := c.processReturnValue(ret)
-	utils.Debug("ZCompress Consume flush:%v have:%v err:%v hasMoreOutput:%v", c.lastFlush, have, err, c.hasMoreOutput)
+	logCall(c.logger, "compress consume", c.zstream, c.lastFlush, -1, have, c.hasMoreOutput, err)
 	return have, err
 }
 
@@ -121,7 +399,7 @@ func (c *compressor) processReturnValue(ret capi.ZConstant) error {
 		// Z_STREAM_ERROR indicates that the stream state was inconsistent
 		// which may happen if the stream was not initialized
 		// Or the appplication is broken and altered the memory of the stream state.
-		reason := fmt.Errorf("zlib: deflate failed with err: %w", capi.ZError(ret))
+		reason := c.zstream.Error(capi.OpDeflate, ret)
 		return c.endStream(reason)
 	}
 
@@ -130,7 +408,7 @@ func (c *compressor) processReturnValue(ret capi.ZConstant) error {
 		// At this point, the input should have been fully consumed.
 		if c.zstream.AvailIn() > 0 {
 			// This should never happen, but who knows!
-			reason := fmt.Errorf("zlib: no more output but the input is not fully consumed: %w", capi.ZError(ret))
+			reason := fmt.Errorf("zlib: no more output but the input is not fully consumed: %w", c.zstream.Error(capi.OpDeflate, ret))
 			return c.endStream(reason)
 		}
 
@@ -138,14 +416,11 @@ func (c *compressor) processReturnValue(ret capi.ZConstant) error {
 			// If flush is Z_FINISH, then we should have consumed all input and output all data
 			// and ret should be Z_STREAM_END
 			if ret != capi.Z_STREAM_END {
-				reason := fmt.Errorf("zlib: no more output, flush is requested, but the result is not Z_STREAM_END: %w", capi.ZError(ret))
+				reason := fmt.Errorf("zlib: no more output, flush is requested, but the result is not Z_STREAM_END: %w", c.zstream.Error(capi.OpDeflate, ret))
 				return c.endStream(reason)
 			}
 
-			err := c.endStream(nil)
-			if err != nil {
-				return err
-			}
+			c.endStream(nil)
 			return io.EOF
 		}
 
@@ -164,23 +439,47 @@ func (c *compressor) processReturnValue(ret capi.ZConstant) error {
 	return nil
 }
 
-// deflateEnd needs to be called when ZStream is no longer needed
+// endStream is called when the stream has successfully ended or when an unrecoverable error has occurred.
+// When the stream has ended because of an error, deflateEnd is called right away.
+// When the stream has ended successfully, the zlib state is kept so that it can be reused by Reset until Close is called.
 func (c *compressor) endStream(reason error) error {
-	endRet := c.zstream.DeflateEnd()
 	c.streamEndHasBeenCalled = true
-
 	c.streamEndReason = reason
-	c.streamEndError = processStreamEndError(reason, endRet)
+	c.streamEndError = nil
+	if reason != nil {
+		endRet := c.zstream.DeflateEnd()
+		c.released = true
+		c.streamEndError = processStreamEndError(reason, endRet)
+	}
+	c.observation.streamEnd(reason, c.Stats)
 	return c.streamEndError
 }
 
+// Close calls deflateEnd if it has not been called yet.
+func (c *compressor) Close() error {
+	if c.released {
+		return nil
+	}
+
+	endRet := c.zstream.DeflateEnd()
+	c.released = true
+	if !c.streamEndHasBeenCalled {
+		// The stream is closed before it ended. deflateEnd returns Z_DATA_ERROR in that case which is expected.
+		c.streamEndHasBeenCalled = true
+		c.streamEndReason = errClosed
+		c.streamEndError = errClosed
+		return nil
+	}
+	return processStreamEndError(nil, endRet)
+}
+
 func processStreamEndError(reason error, endRet capi.ZConstant) error {
 	if reason != nil {
-		return wrapWithDistructionNote(reason, capi.ZError(endRet))
+		return wrapWithDistructionNote(reason, capi.ZErrorOp(capi.OpEnd, endRet))
 	}
 
 	if endRet != capi.Z_OK {
-		return fmt.Errorf("zlib: the stream ended successfully but distruction failed: %w", capi.ZError(endRet))
+		return fmt.Errorf("zlib: the stream ended successfully but distruction failed: %w", capi.ZErrorOp(capi.OpEnd, endRet))
 	}
 
 	return nil
@@ -190,28 +489,29 @@ func zFlush(flush Flush) capi.ZConstant {
 	switch flush {
 	case NoFlush:
 		return capi.Z_NO_FLUSH
+	case PartialFlush:
+		return capi.Z_PARTIAL_FLUSH
 	case SyncFlush:
 		return capi.Z_SYNC_FLUSH
 	case Finish:
 		return capi.Z_FINISH
+	case FullFlush:
+		return capi.Z_FULL_FLUSH
+	case Block:
+		return capi.Z_BLOCK
+	case Trees:
+		return capi.Z_TREES
 	default:
 		panic("invalid flush")
 	}
 }
 
+// errAutoHeaderCompression is returned when compress options use HeaderTypeAuto.
+var errAutoHeaderCompression = fmt.Errorf("zlib: HeaderTypeAuto is only supported for decompression. Use HeaderTypeZlib, HeaderTypeGzip or HeaderTypeRaw to compress")
+
+// errClosed is the reason reported when a stream is used after it has been closed before it ended.
+var errClosed = fmt.Errorf("zlib: the stream has been closed")
+
 func wrapWithDistructionNote(originalError error, destructionError error) error {
 	return fmt.Errorf("%w. The stream is no longer usable and was destructed. The result of stream destruction is: %s", originalError, destructionError)
 }
-
-// TODO
-// With flush set to Z_FINISH, this final set of deflate() calls will complete
-// the output stream. Once that is done, subsequent calls of deflate() would
-// return Z_STREAM_ERROR if the flush parameter is not Z_FINISH, and do no more
-// processing until the state is reinitialized (call deflateInit).
-// func (c *ZCompressor2) Reset() err {
-
-// 	ret := c.zstream.DeflateInit2(opts.Level, opts.ZWindowBits(), opts.MemoryLevel, int(opts.Strategy))
-// 	if ret != Z_OK {
-// 		return nil, ZError(ret)
-// 	}
-// }
@@ -0,0 +1,11 @@
+package compression
+
+import "context"
+
+// contextErr returns the error of ctx once it is done. ctx can be nil.
+func contextErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
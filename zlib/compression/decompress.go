@@ -1,49 +1,88 @@
 package compression
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 
 	"github.com/MeenaAlfons/go-zlib/zlib/capi"
 	"github.com/MeenaAlfons/go-zlib/zlib/common"
-	"github.com/MeenaAlfons/go-zlib/zlib/utils"
 )
 
 // NewDecompressor creates a new decompressor FeederConsumer with the given options.
-func NewDecompressor(opts common.DecompressOptions) (FeederConsumer, error) {
+// The zlib state is kept after the end of the stream so that it can be reused by Reset. Close must be called to release it.
+func NewDecompressor(opts common.DecompressOptions) (Decompressor, error) {
 	c := &decompressor{
-		zstream: capi.NewZStream(),
+		zstream:  capi.NewZStream(),
+		released: true,
 	}
 
-	ret := c.zstream.InflateInit2(zWindowBits(opts))
-	if ret != capi.Z_OK {
-		return nil, capi.ZError(ret)
-	}
-
-	switch opts.Header() {
-	case common.HeaderTypeZlib:
-		// Save the initial dictionary to be used later after the first inflate call returns Z_NEED_DICT.
-		c.initialDictionary = opts.InitialDictionary()
-	case common.HeaderTypeRaw:
-		if opts.InitialDictionary() != nil {
-			ret = c.zstream.InflateSetDictionary(opts.InitialDictionary())
-			if ret != capi.Z_OK {
-				return nil, capi.ZError(ret)
-			}
-		}
-
+	err := c.Reset(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	return newFeederConsumerSafeOutputBuffer(c), nil
+	return newDecompressorSafeOutputBuffer(c), nil
 }
 
 type decompressor struct {
 	zstream           capi.ZStream
+	header            common.HeaderType
 	initialDictionary []byte
+	resolver          common.DictionaryResolver
+	// dictionarySet is true when the dictionary requested by the current member has been set.
+	dictionarySet bool
+
+	// windowBits and dictionary are kept to start the next member of a multi-member stream.
+	windowBits int
+	dictionary []byte
+
+	multiMember   bool
+	memberHandler common.MemberHandler
+	// betweenMembers is true when the input fed so far ended right at the end of a member.
+	betweenMembers bool
+
+	// remaining is a copy of the input that was not consumed when the stream ended.
+	remaining []byte
+
+	// released is true when the zlib state is not allocated.
+	// This is the case before initialization, after an unrecoverable error and after Close.
+	released bool
 
 	lastFlush     Flush
 	hasMoreOutput bool
 
+	// fed is true when Feed has been called since the stream started.
+	fed bool
+	// flushes is the number of calls to Feed with a flush other than NoFlush since the stream started.
+	flushes int64
+	// observation reports the events of the stream to the observer of the options.
+	observation observation
+	// logger is the logger of the options. nil uses the global logger.
+	logger *slog.Logger
+	// ctx is the context of the options checked before each call to zlib. It can be nil.
+	ctx context.Context
+
+	// maxOutputSize, maxRatio and maxWindowBits are the limits of the options. 0 disables a limit.
+	maxOutputSize int64
+	maxRatio      float64
+	maxWindowBits int
+	// inspectHeader is true when the window declared by the zlib header of the member has to be checked against maxWindowBits.
+	inspectHeader bool
+
+	// previousTotalIn and previousTotalOut are the totals of the previous members of a multi-member stream.
+	// The totals of zlib are reset at the start of each member.
+	previousTotalIn  int64
+	previousTotalOut int64
+
+	// recovery is called with each region skipped to recover from corrupted data. nil disables recovery.
+	recovery common.RecoveryHandler
+	// syncing is true while the corrupted data is being skipped looking for a full flush point.
+	syncing bool
+	// skipped is the region being skipped while syncing.
+	skipped common.SkippedRegion
+
 	// StreamEnd is called when the stream has successfully ended or when an unrecoverable error has occurred
 	streamEndHasBeenCalled bool
 
@@ -56,6 +95,121 @@ type decompressor struct {
 	streamEndReason error
 }
 
+// Reset discards the current state and starts a new stream with the given options.
+// inflateReset2 is used when the zlib state is still allocated. Otherwise, the zlib state is initialized again.
+func (c *decompressor) Reset(opts common.DecompressOptions) error {
+	c.observation = observation{observer: opts.Observer(), direction: common.DirectionDecompress}
+	c.logger = opts.Logger()
+	c.ctx = opts.Context()
+	c.maxOutputSize = opts.MaxOutputSize()
+	c.maxRatio = opts.MaxRatio()
+	c.maxWindowBits = opts.MaxWindowBits()
+	c.zstream.SetLogger(c.logger)
+	if !c.released {
+		ret := c.zstream.InflateReset2(decompressWindowBits(opts))
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpReset, ret))
+		}
+	} else {
+		ret := c.zstream.InflateInit2(decompressWindowBits(opts))
+		if ret != capi.Z_OK {
+			c.streamEndHasBeenCalled = true
+			c.streamEndReason = c.zstream.Error(capi.OpInit, ret)
+			c.streamEndError = c.streamEndReason
+			c.observation.streamEnd(c.streamEndReason, nil)
+			return c.streamEndError
+		}
+		c.released = false
+	}
+
+	c.header = opts.Header()
+	c.windowBits = decompressWindowBits(opts)
+	c.dictionary = opts.InitialDictionary()
+	c.multiMember = opts.MultiMember()
+	c.memberHandler = opts.MemberHandler()
+	c.resolver = opts.DictionaryResolver()
+	c.betweenMembers = false
+	c.remaining = nil
+	c.lastFlush = NoFlush
+	c.hasMoreOutput = false
+	c.fed = false
+	c.flushes = 0
+	c.previousTotalIn = 0
+	c.previousTotalOut = 0
+	c.recovery = opts.Recovery()
+	c.syncing = false
+	c.streamEndHasBeenCalled = false
+	c.streamEndError = nil
+	c.streamEndReason = nil
+	c.observation.streamInit()
+
+	return c.startMember()
+}
+
+// startMember prepares the stream to decompress a member after initialization or reset.
+func (c *decompressor) startMember() error {
+	c.initialDictionary = nil
+	c.dictionarySet = false
+	c.inspectHeader = c.maxWindowBits > 0 && (c.header == common.HeaderTypeZlib || c.header == common.HeaderTypeAuto)
+	switch c.header {
+	case common.HeaderTypeAuto:
+		// Save the initial dictionary in case the stream turns out to be a zlib stream requesting a dictionary.
+		c.initialDictionary = c.dictionary
+		// Request the gzip header to be stored in case the stream turns out to be a gzip stream.
+		// This is also used to detect the header type.
+		ret := c.zstream.InflateGetHeader()
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpGetHeader, ret))
+		}
+	case common.HeaderTypeZlib:
+		// Save the initial dictionary to be used later after the first inflate call returns Z_NEED_DICT.
+		c.initialDictionary = c.dictionary
+	case common.HeaderTypeRaw:
+		if c.dictionary != nil {
+			ret := c.zstream.InflateSetDictionary(c.dictionary)
+			if ret != capi.Z_OK {
+				return c.endStream(c.zstream.Error(capi.OpSetDictionary, ret))
+			}
+		}
+	case common.HeaderTypeGzip:
+		if c.dictionary != nil {
+			return c.endStream(fmt.Errorf("zlib: initial dictionary is not supported with gzip header"))
+		}
+		// Request the gzip header to be stored so that it can be reported by GzipHeader.
+		ret := c.zstream.InflateGetHeader()
+		if ret != capi.Z_OK {
+			return c.endStream(c.zstream.Error(capi.OpGetHeader, ret))
+		}
+	}
+
+	return nil
+}
+
+// Prime inserts the low bits of value in front of the input.
+// It is used to start decompressing a raw deflate bitstream in the middle of a byte.
+// It can only be used for raw deflate and before the first call to Feed.
+// A negative bits discards the bits inserted so far.
+func (c *decompressor) Prime(bits, value int) error {
+	if c.streamEndHasBeenCalled {
+		return fmt.Errorf("zlib: stream has ended and cannot be used anymore. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+
+	if c.header != common.HeaderTypeRaw {
+		return fmt.Errorf("zlib: Prime can only be used with raw deflate")
+	}
+
+	if c.fed {
+		return fmt.Errorf("zlib: Prime can only be called before the first call to Feed")
+	}
+
+	ret := c.zstream.InflatePrime(bits, value)
+	if ret != capi.Z_OK {
+		// Z_STREAM_ERROR indicates that bits is invalid or that the bit buffer is full. The stream is still usable.
+		return c.zstream.Error(capi.OpPrime, ret)
+	}
+	return nil
+}
+
 // Make sure that the input buffer has capacity larger than its size by at least one.
 // This is to avoid the case where the stream ends at the end of the buffer which would
 // result in an internal state that points past the end of the buffer and causes an error
@@ -80,16 +234,51 @@ func (c *decompressor) Feed(input []byte, flush Flush, outputBuffer []byte) (int
 		return 0, fmt.Errorf("feed: cannot call Feed when there is still output to be consumed. Call Consume instead. Always check CanCallConsume")
 	}
 
+	if err := contextErr(c.ctx); err != nil {
+		return 0, c.endStream(err)
+	}
+
 	c.lastFlush = flush
+	c.fed = true
+	if flush != NoFlush {
+		c.flushes++
+		c.observation.flush()
+	}
 	zflush := zFlush(c.lastFlush)
 
+	if c.betweenMembers {
+		if len(input) == 0 {
+			if flush == Finish {
+				c.endStream(nil)
+				return 0, io.EOF
+			}
+			return 0, nil
+		}
+		c.betweenMembers = false
+		if err := c.resetMember(); err != nil {
+			return 0, err
+		}
+	}
+
 	c.zstream.SetInput(input)
-	c.zstream.SetOutput(outputBuffer)
+	c.zstream.SetOutput(c.limitOutput(outputBuffer))
+	if c.syncing {
+		// Inflate resumes in Consume if a full flush point is found.
+		return 0, c.sync()
+	}
+	if err := c.checkHeaderWindow(); err != nil {
+		return 0, err
+	}
+	zcall := c.observation.begin(c.zstream, capi.OpInflate)
 	ret := c.zstream.Inflate(zflush)
+	c.observation.feed(c.observation.end(c.zstream, zcall))
 	have := c.zstream.ProducedOutput()
 	c.hasMoreOutput = c.zstream.OutputBufferIsFull()
-	err := c.processReturnValue(ret)
-	utils.Debug("ZDecompressor Feed flush:%v have:%v err:%v hasMoreOutput:%v len(input):%v len(output):%v", c.lastFlush, have, err, c.hasMoreOutput, len(input), len(outputBuffer))
+	have, err := c.checkLimits(have)
+	if err == nil {
+		err = c.processReturnValue(ret)
+	}
+	logCall(c.logger, "decompress feed", c.zstream, c.lastFlush, len(input), have, c.hasMoreOutput, err)
 	return have, err
 }
 
@@ -97,6 +286,79 @@ func (c *decompressor) IsDoneWithReason() (bool, error) {
 	return c.streamEndHasBeenCalled, c.streamEndReason
 }
 
+// Remaining returns the input that was fed after the end of the stream and was not consumed.
+// It is nil until the stream ends. In a multi-member stream, input after the end of a member
+// is the next member and is never remaining.
+func (c *decompressor) Remaining() []byte {
+	return c.remaining
+}
+
+// GetDictionary returns the sliding window of inflate which holds up to the last 32 KiB of output.
+// Together with the position in the compressed data, it is what is needed to resume decompression from there.
+// It is still available after the stream ended until Close is called.
+// zlib only keeps a window when decompression takes more than one call to inflate, so it is empty
+// when the whole stream was decompressed by the first call.
+func (c *decompressor) GetDictionary() ([]byte, error) {
+	if c.released {
+		return nil, fmt.Errorf("zlib: stream has been released. Stream ended with reason: %v, err: %v", c.streamEndReason, c.streamEndError)
+	}
+
+	dictionary, ret := c.zstream.InflateGetDictionary()
+	if ret != capi.Z_OK {
+		return nil, c.zstream.Error(capi.OpGetDictionary, ret)
+	}
+	return dictionary, nil
+}
+
+// Stats returns the statistics of the current stream.
+// For a multi-member stream, the totals cover all the members so far.
+func (c *decompressor) Stats() common.Stats {
+	stats := common.Stats{
+		TotalIn:  c.previousTotalIn + c.zstream.TotalIn(),
+		TotalOut: c.previousTotalOut + c.zstream.TotalOut(),
+		DataType: common.DataTypeUnknown,
+		Flushes:  c.flushes,
+	}
+	if stats.TotalIn > 0 {
+		stats.Ratio = float64(stats.TotalOut) / float64(stats.TotalIn)
+	}
+	if header, ok := c.DetectedHeader(); ok && header != common.HeaderTypeRaw {
+		stats.Checksum = c.zstream.Adler()
+	}
+	// The data_type of inflate does not hold the type of the data. The gzip header tells when the data is text.
+	if header, ok := c.GzipHeader(); ok && header.Text {
+		stats.DataType = common.DataTypeText
+	}
+	return stats
+}
+
+// GzipHeader returns the gzip header once inflate is done reading it.
+// It is only available when the decompressor is created with HeaderTypeGzip.
+func (c *decompressor) GzipHeader() (*common.GzipHeader, bool) {
+	zheader, ok := c.zstream.GzipHeader()
+	if !ok {
+		return nil, false
+	}
+	return fromZGzipHeader(zheader), true
+}
+
+// DetectedHeader returns the header type of the stream.
+// With HeaderTypeAuto, it returns false until inflate has read enough of the stream to detect the header.
+func (c *decompressor) DetectedHeader() (common.HeaderType, bool) {
+	if c.header != common.HeaderTypeAuto {
+		return c.header, true
+	}
+
+	switch c.zstream.GzipHeaderDone() {
+	case 1:
+		return common.HeaderTypeGzip, true
+	case -1:
+		return common.HeaderTypeZlib, true
+	default:
+		return common.HeaderTypeAuto, false
+	}
+}
+
 func (c *decompressor) CanCallConsume() bool {
 	// This is not totally correct, I think there is a case where there is still more input but the decompression has ended, there is not more input needed, and the output buffer is not full.
 	// In that case, there is no point of calling Consume again!
@@ -128,13 +390,25 @@ func (c *decompressor) Consume(outputBuffer []byte) (int, error) {
 		return 0, nil
 	}
 
+	if err := contextErr(c.ctx); err != nil {
+		return 0, c.endStream(err)
+	}
+
 	zflush := zFlush(c.lastFlush)
-	c.zstream.SetOutput(outputBuffer)
+	c.zstream.SetOutput(c.limitOutput(outputBuffer))
+	if err := c.checkHeaderWindow(); err != nil {
+		return 0, err
+	}
+	zcall := c.observation.begin(c.zstream, capi.OpInflate)
 	ret := c.zstream.Inflate(zflush)
+	c.observation.consume(c.observation.end(c.zstream, zcall))
 	have := c.zstream.ProducedOutput()
 	c.hasMoreOutput = c.zstream.OutputBufferIsFull()
-	err := c.processReturnValue(ret)
-	utils.Debug("ZDecompressor Consume flush:%v have:%v err:%v hasMoreOutput:%v", c.lastFlush, have, err, c.hasMoreOutput)
+	have, err := c.checkLimits(have)
+	if err == nil {
+		err = c.processReturnValue(ret)
+	}
+	logCall(c.logger, "decompress consume", c.zstream, c.lastFlush, -1, have, c.hasMoreOutput, err)
 	return have, err
 }
 
@@ -145,28 +419,39 @@ func (c *decompressor) processReturnValue(ret capi.ZConstant) error {
 	// Z_STREAM_ERROR indicates that the stream state was inconsistent
 	//                which may happen if the stream was not initialized
 	//                Or the appplication is broken and altered the memory of the stream state.
+	if ret == capi.Z_DATA_ERROR && c.recovery != nil {
+		c.syncing = true
+		c.skipped = common.SkippedRegion{
+			Offset: c.previousTotalIn + c.zstream.TotalIn(),
+			Err:    c.zstream.Error(capi.OpInflate, ret),
+		}
+		return c.sync()
+	}
+
 	switch ret {
 	case capi.Z_DATA_ERROR, capi.Z_MEM_ERROR, capi.Z_STREAM_ERROR:
-		reason := fmt.Errorf("zlib: inflate failed with err: %w", capi.ZError(ret))
-		return c.endStream(reason)
+		return c.endStream(c.zstream.Error(capi.OpInflate, ret))
 	case capi.Z_NEED_DICT:
-		if c.initialDictionary == nil {
-			reason := fmt.Errorf("zlib: inflate failed with err: %w", capi.ZError(ret))
-			return c.endStream(reason)
+		dictionary, err := c.neededDictionary(c.zstream.Error(capi.OpInflate, ret))
+		if err != nil {
+			return c.endStream(err)
 		}
-		ret2 := c.zstream.InflateSetDictionary(c.initialDictionary)
+		ret2 := c.zstream.InflateSetDictionary(dictionary)
 		if ret2 != capi.Z_OK {
-			reason := fmt.Errorf("zlib: inflate failed with err: %w, and InflateSetDictionary failed with err: %w", capi.ZError(ret), capi.ZError(ret2))
+			reason := fmt.Errorf("zlib: the dictionary requested by inflate could not be set: %w", c.zstream.Error(capi.OpSetDictionary, ret2))
 			return c.endStream(reason)
 		}
-		// Clear the initial dictionary so that it is not used again.
 		// This will help us catch the case where Z_NEED_DICT is returned again instead of mistakenly providing the same dictionary.
-		c.initialDictionary = nil
+		c.dictionarySet = true
 		// Set hasMoreOutput to true so that Consume is called instead of Feed.
 		c.hasMoreOutput = true
 		return nil
 	}
 
+	if ret == capi.Z_STREAM_END && c.multiMember {
+		return c.endMember()
+	}
+
 	if !c.hasMoreOutput {
 		// If there is no more output, then it is one of the following cases:
 		// - The input is not fully consumed:
@@ -178,30 +463,37 @@ func (c *decompressor) processReturnValue(ret capi.ZConstant) error {
 
 		// If the input is not fully consumed
 		if c.zstream.AvailIn() > 0 {
-			// This should not be an actual error.
+			// With flush = Block or Trees, inflate stops at a block boundary before consuming all the input.
+			// Set hasMoreOutput to true so that Consume is called to continue with the rest of the input.
+			if (c.lastFlush == Block || c.lastFlush == Trees) && ret == capi.Z_OK {
+				c.hasMoreOutput = true
+				return nil
+			}
+
+			// The stream ended before the end of the input. The rest of the input is kept for Remaining.
 			if ret == capi.Z_STREAM_END {
-				reason := fmt.Errorf("decompression ended but the input was not fully consumed. %w", capi.ZError(ret))
-				return c.endStream(reason)
+				c.remaining = append([]byte(nil), c.zstream.UnconsumedInput()...)
+				c.reportMember()
+				c.endStream(nil)
+				return io.EOF
 			}
 
 			// This should never happen, but who knows!
-			reason := fmt.Errorf("the input was not fully consumed and decompression has not ended (ret != Z_STREAM_END). %w", capi.ZError(ret))
+			reason := fmt.Errorf("the input was not fully consumed and decompression has not ended (ret != Z_STREAM_END). %w", c.zstream.Error(capi.OpInflate, ret))
 			return c.endStream(reason)
 		}
 
 		// The input is fully consumed.
 		if ret == capi.Z_STREAM_END {
-			err := c.endStream(nil)
-			if err != nil {
-				return err
-			}
+			c.reportMember()
+			c.endStream(nil)
 			return io.EOF
 		}
 
 		if c.lastFlush == Finish {
 			// If flush is Z_FINISH, then decompression should have ended with ret = Z_STREAM_END.
 			// This indicates that the compressed data is corrupted.
-			reason := fmt.Errorf("the end of input was reached (flush=finish) but decompression was not done. The compressed data is probably corrupted. %w", capi.ZError(ret))
+			reason := fmt.Errorf("the end of input was reached (flush=finish) but decompression was not done. The compressed data is probably corrupted. %w", c.zstream.Error(capi.OpInflate, ret))
 			return c.endStream(reason)
 		}
 
@@ -220,30 +512,175 @@ func (c *decompressor) processReturnValue(ret capi.ZConstant) error {
 	// TODO: We also happen to get here with Z_STREAM_END which means that the stream ended but
 	//       we still have more output to be consumed. I'll allow it for now to see what happens
 	//       when Consume is called again
-	utils.Debug("There is still more output to be consumed. ret: %v", ret)
 	if ret != capi.Z_BUF_ERROR && ret != capi.Z_OK && ret != capi.Z_STREAM_END {
-		reason := fmt.Errorf("zlib: more output is available but ret is not Z_BUF_ERROR, Z_OK, nor Z_STREAM_END: %w", capi.ZError(ret))
+		reason := fmt.Errorf("zlib: more output is available but ret is not Z_BUF_ERROR, Z_OK, nor Z_STREAM_END: %w", c.zstream.Error(capi.OpInflate, ret))
 		return c.endStream(reason)
 	}
 	return nil
 }
 
-// inflateEnd needs to be called when ZStream is no longer needed
-// Both when the stream is ended and when there is an error.
+// endMember is called when a member ends in a multi-member stream.
+// Decompression continues with the next member in the rest of the input if any.
+// Otherwise, the next member is started by the next call to Feed unless it is called with Finish and no input.
+func (c *decompressor) endMember() error {
+	c.reportMember()
+
+	if c.zstream.AvailIn() > 0 {
+		if err := c.resetMember(); err != nil {
+			return err
+		}
+		// Set hasMoreOutput to true so that Consume is called to continue with the next member.
+		c.hasMoreOutput = true
+		return nil
+	}
+
+	// All the output of the member has been produced even if the output buffer is full.
+	c.hasMoreOutput = false
+	if c.lastFlush == Finish {
+		c.endStream(nil)
+		return io.EOF
+	}
+	// The stream is reset later so that the header of the last member is still reported after the stream ends.
+	c.betweenMembers = true
+	return nil
+}
+
+// neededDictionary returns the dictionary requested by inflate after it returned Z_NEED_DICT.
+// inflate reports the id of the requested dictionary, which is its Adler-32 checksum, in strm.adler.
+// The initial dictionary is used if its id matches. Otherwise, the dictionary is asked from the resolver.
+// needDict is the error of inflate which is wrapped by the returned error when no dictionary is found.
+func (c *decompressor) neededDictionary(needDict error) ([]byte, error) {
+	if c.dictionarySet {
+		return nil, needDict
+	}
+
+	id := c.zstream.Adler()
+	if c.initialDictionary != nil && common.DictionaryID(c.initialDictionary) == id {
+		return c.initialDictionary, nil
+	}
+
+	if c.resolver == nil {
+		if c.initialDictionary != nil {
+			return nil, fmt.Errorf("zlib: the stream needs the dictionary with id %08x but the initial dictionary has id %08x: %w", id, common.DictionaryID(c.initialDictionary), needDict)
+		}
+		return nil, needDict
+	}
+
+	dictionary, err := c.resolver(id)
+	if err != nil {
+		return nil, fmt.Errorf("zlib: resolving the dictionary with id %08x failed: %w: %w", id, err, needDict)
+	}
+	if resolvedID := common.DictionaryID(dictionary); resolvedID != id {
+		return nil, fmt.Errorf("zlib: the stream needs the dictionary with id %08x but the resolver returned a dictionary with id %08x: %w", id, resolvedID, needDict)
+	}
+	return dictionary, nil
+}
+
+// resetMember resets the stream to decompress the next member while keeping the rest of the input.
+func (c *decompressor) resetMember() error {
+	c.previousTotalIn += c.zstream.TotalIn()
+	c.previousTotalOut += c.zstream.TotalOut()
+	ret := c.zstream.InflateReset()
+	if ret != capi.Z_OK {
+		return c.endStream(c.zstream.Error(capi.OpReset, ret))
+	}
+	return c.startMember()
+}
+
+// reportMember reports the member that has just ended to the member handler.
+func (c *decompressor) reportMember() {
+	if c.memberHandler == nil {
+		return
+	}
+	header, _ := c.DetectedHeader()
+	member := common.Member{
+		Header:           header,
+		CompressedSize:   c.zstream.TotalIn(),
+		UncompressedSize: c.zstream.TotalOut(),
+	}
+	if header != common.HeaderTypeRaw {
+		member.Checksum = c.zstream.Adler()
+	}
+	if gzipHeader, ok := c.GzipHeader(); ok {
+		member.GzipHeader = gzipHeader
+	}
+	c.memberHandler(member)
+}
+
+// sync skips the corrupted data in the available input looking for a full flush point.
+// When one is found, hasMoreOutput is set so that Consume resumes inflate with the rest of the input.
+// Otherwise, the rest of the corrupted data is skipped by the next call to Feed.
+func (c *decompressor) sync() error {
+	ret := c.zstream.InflateSync()
+	switch ret {
+	case capi.Z_OK:
+		c.endSkippedRegion()
+		c.hasMoreOutput = true
+		return nil
+	case capi.Z_DATA_ERROR, capi.Z_BUF_ERROR:
+		// All the available input has been skipped without finding a full flush point.
+		c.hasMoreOutput = false
+		if c.lastFlush == Finish {
+			c.endSkippedRegion()
+			reason := fmt.Errorf("zlib: the input ended while skipping corrupted data: %w", c.skipped.Err)
+			return c.endStream(reason)
+		}
+		return nil
+	default:
+		return c.endStream(c.zstream.Error(capi.OpSync, ret))
+	}
+}
+
+// endSkippedRegion reports the region skipped so far.
+func (c *decompressor) endSkippedRegion() {
+	c.syncing = false
+	c.skipped.Length = c.previousTotalIn + c.zstream.TotalIn() - c.skipped.Offset
+	c.recovery(c.skipped)
+}
+
+// endStream is called when the stream has successfully ended or when an unrecoverable error has occurred.
+// When the stream has ended because of an error, inflateEnd is called right away.
+// When the stream has ended successfully, the zlib state is kept so that it can be reused by Reset until Close is called.
 func (c *decompressor) endStream(reason error) error {
-	endRet := c.zstream.InflateEnd()
 	c.streamEndHasBeenCalled = true
-
 	c.streamEndReason = reason
-	c.streamEndError = processStreamEndError(reason, endRet)
+	c.streamEndError = nil
+	if reason != nil {
+		endRet := c.zstream.InflateEnd()
+		c.released = true
+		c.streamEndError = processStreamEndError(reason, endRet)
+	}
+	c.observation.streamEnd(reason, c.Stats)
 	return c.streamEndError
 }
 
-// TODO
-// func (c *ZDecompressor2) Reset() err {
+// ClearOptions drops the context, observer, logger, handlers and dictionaries of the options and the remaining input
+// while keeping the zlib state.
+func (c *decompressor) ClearOptions() {
+	c.observation = observation{direction: common.DirectionDecompress}
+	c.logger = nil
+	c.ctx = nil
+	c.zstream.SetLogger(nil)
+	c.initialDictionary = nil
+	c.dictionary = nil
+	c.resolver = nil
+	c.memberHandler = nil
+	c.recovery = nil
+	c.remaining = nil
+}
 
-// 	ret := c.zstream.DeflateInit2(opts.Level, opts.ZWindowBits(), opts.MemoryLevel, int(opts.Strategy))
-// 	if ret != Z_OK {
-// 		return nil, ZError(ret)
-// 	}
-// }
+// Close calls inflateEnd if it has not been called yet.
+func (c *decompressor) Close() error {
+	if c.released {
+		return nil
+	}
+
+	endRet := c.zstream.InflateEnd()
+	c.released = true
+	if !c.streamEndHasBeenCalled {
+		c.streamEndHasBeenCalled = true
+		c.streamEndReason = errClosed
+		c.streamEndError = errClosed
+	}
+	return processStreamEndError(nil, endRet)
+}
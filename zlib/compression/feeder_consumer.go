@@ -1,16 +1,59 @@
 package compression
 
+import (
+	"fmt"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
 // flush has two meanings:
 // - It can be used to force flushing as much output as possible, like concluding the compression of the current input allowing this block to be decompressed independently from the next block.
 // - It can be used to indicate that the stream has ended and no more input will be fed.
+//
+// The values map to the flush values of zlib. For more details, see deflate() and inflate() in http://zlib.net/manual.html#Basic
 type Flush int
 
 const (
-	NoFlush   Flush = 0
+	// NoFlush lets zlib decide how much data to accumulate before producing output.
+	NoFlush Flush = 0
+	// PartialFlush flushes the pending output without aligning it to a byte boundary. Deprecated in zlib in favor of SyncFlush.
+	PartialFlush Flush = 1
+	// SyncFlush flushes all pending output aligned on a byte boundary so that the decompressor can get all input data so far.
 	SyncFlush Flush = 2
-	Finish    Flush = 3
+	// Finish concludes the stream. No more input can be fed after Finish.
+	Finish Flush = 3
+	// FullFlush flushes like SyncFlush and resets the compression state so that decompression can restart from this point
+	// if the previous compressed data has been damaged or if random access is desired. It degrades compression if used too often.
+	FullFlush Flush = 4
+	// Block completes the current deflate block without aligning the output on a byte boundary.
+	// When decompressing, inflate stops at the next deflate block boundary.
+	Block Flush = 5
+	// Trees is like Block. When decompressing, inflate also stops at the end of each deflate block header.
+	// It is not supported for compression.
+	Trees Flush = 6
 )
 
+func (f Flush) String() string {
+	switch f {
+	case NoFlush:
+		return "NoFlush"
+	case PartialFlush:
+		return "PartialFlush"
+	case SyncFlush:
+		return "SyncFlush"
+	case Finish:
+		return "Finish"
+	case FullFlush:
+		return "FullFlush"
+	case Block:
+		return "Block"
+	case Trees:
+		return "Trees"
+	default:
+		return fmt.Sprintf("Flush(%d)", int(f))
+	}
+}
+
 // FeederConsumer is an interface that allows feeding input and consuming output.
 // It represents a component that takes input and produces output in a streaming fashion.
 // It is used to implement both compression and decompression.
@@ -19,6 +62,7 @@ type FeederConsumer interface {
 	// If the output buffer is not large enough, it writes as much as possible to the output buffer.
 	// The rest of the output needs to be consumed by caling Consume.
 	// flush can be used to force flushing as much output as possible, or to indicate the end of the stream.
+	// All flush values are supported. See Flush for their meaning.
 	Feed(input []byte, flush Flush, outputBuffer []byte) (int, error)
 
 	// Consume consumes output from the stream. It returns the number of bytes written to the output buffer.
@@ -27,7 +71,72 @@ type FeederConsumer interface {
 	// CanCallConsume returns true if there is output that needs to be consumed by calling Consume.
 	CanCallConsume() bool
 
+	// Stats returns the statistics of the current stream.
+	Stats() common.Stats
+
 	// IsDoneWithReason returns true if the stream has ended.
 	// If the stream has ended because of an error, it returns the error.
 	IsDoneWithReason() (bool, error)
+
+	// Close releases the resources held by the stream.
+	// The resources of a stream that ended successfully are kept until Close is called so that they can be reused by Reset.
+	// The FeederConsumer can not be used after Close unless it is reset.
+	Close() error
+}
+
+// Compressor is a FeederConsumer that compresses data.
+type Compressor interface {
+	FeederConsumer
+
+	// Reset discards the current state and starts a new stream with the given options.
+	// The allocated resources are reused whenever the options allow it.
+	Reset(opts common.CompressOptions) error
+
+	// SetParams changes the compression level and strategy for the input fed after this call.
+	// The input fed so far must be flushed first with any flush other than NoFlush, for example Block.
+	SetParams(level int, strategy common.StrategyType) error
+
+	// Prime inserts the low bits of value at the start of the output of a raw deflate stream.
+	// It must be called before the first call to Feed. bits must be at most 16.
+	Prime(bits, value int) error
+
+	// Pending returns the number of bytes and bits of output that are buffered inside zlib.
+	Pending() (pendingBytes int, pendingBits int, err error)
+
+	// Bound returns an upper bound on the size of the output for sourceLength bytes of input fed in a single call with Finish.
+	Bound(sourceLength int) (int, error)
+
+	// GetDictionary returns the sliding window of deflate which holds up to the last 32 KiB of input.
+	GetDictionary() ([]byte, error)
+
+	// ClearOptions drops the context, observer and logger of the options while keeping the zlib state.
+	// It is used before keeping the Compressor around, for example in a pool. Reset sets them again.
+	ClearOptions()
+}
+
+// Decompressor is a FeederConsumer that decompresses data.
+// In addition to feeding and consuming, it reports information read from the stream.
+type Decompressor interface {
+	FeederConsumer
+	common.GzipHeaderGetter
+	common.HeaderDetector
+
+	// Reset discards the current state and starts a new stream with the given options.
+	// The allocated resources are reused whenever the options allow it.
+	Reset(opts common.DecompressOptions) error
+
+	// Prime inserts the low bits of value in front of the input of a raw deflate stream.
+	// It must be called before the first call to Feed. bits must be at most 16.
+	// A negative bits discards the bits inserted so far.
+	Prime(bits, value int) error
+
+	// Remaining returns the input that was fed after the end of the stream and was not consumed.
+	Remaining() []byte
+
+	// GetDictionary returns the sliding window of inflate which holds up to the last 32 KiB of output.
+	GetDictionary() ([]byte, error)
+
+	// ClearOptions drops the context, observer, logger, handlers and dictionaries of the options and the remaining input
+	// while keeping the zlib state. It is used before keeping the Decompressor around, for example in a pool. Reset sets them again.
+	ClearOptions()
 }
@@ -1,5 +1,7 @@
 package compression
 
+import "github.com/MeenaAlfons/go-zlib/zlib/common"
+
 // When the stream arrives at the end of a buffer, its internal state would refer to a position past the end of the buffer.
 // This results in error: "found pointer to free object".
 // To avoid this case, we reserve one byte at the end of the buffer so that the final state will not point past the end of the buffer.
@@ -47,6 +49,98 @@ func (c *feederConsumerSafeOutputBuffer) CanCallConsume() bool {
 	return c.feederConsumer.CanCallConsume()
 }
 
+func (c *feederConsumerSafeOutputBuffer) Stats() common.Stats {
+	return c.feederConsumer.Stats()
+}
+
 func (c *feederConsumerSafeOutputBuffer) IsDoneWithReason() (bool, error) {
 	return c.feederConsumer.IsDoneWithReason()
 }
+
+func (c *feederConsumerSafeOutputBuffer) Close() error {
+	return c.feederConsumer.Close()
+}
+
+// compressorSafeOutputBuffer applies feederConsumerSafeOutputBuffer to a compressor
+// while still exposing the methods of Compressor that are not part of FeederConsumer.
+type compressorSafeOutputBuffer struct {
+	FeederConsumer
+	compressor *compressor
+}
+
+func newCompressorSafeOutputBuffer(compressor *compressor) Compressor {
+	return &compressorSafeOutputBuffer{
+		FeederConsumer: newFeederConsumerSafeOutputBuffer(compressor),
+		compressor:     compressor,
+	}
+}
+
+func (c *compressorSafeOutputBuffer) Reset(opts common.CompressOptions) error {
+	return c.compressor.Reset(opts)
+}
+
+func (c *compressorSafeOutputBuffer) SetParams(level int, strategy common.StrategyType) error {
+	return c.compressor.SetParams(level, strategy)
+}
+
+func (c *compressorSafeOutputBuffer) Prime(bits, value int) error {
+	return c.compressor.Prime(bits, value)
+}
+
+func (c *compressorSafeOutputBuffer) Pending() (int, int, error) {
+	return c.compressor.Pending()
+}
+
+func (c *compressorSafeOutputBuffer) Bound(sourceLength int) (int, error) {
+	return c.compressor.Bound(sourceLength)
+}
+
+func (c *compressorSafeOutputBuffer) GetDictionary() ([]byte, error) {
+	return c.compressor.GetDictionary()
+}
+
+func (c *compressorSafeOutputBuffer) ClearOptions() {
+	c.compressor.ClearOptions()
+}
+
+// decompressorSafeOutputBuffer applies feederConsumerSafeOutputBuffer to a decompressor
+// while still exposing the methods of Decompressor that are not part of FeederConsumer.
+type decompressorSafeOutputBuffer struct {
+	FeederConsumer
+	decompressor *decompressor
+}
+
+func newDecompressorSafeOutputBuffer(decompressor *decompressor) Decompressor {
+	return &decompressorSafeOutputBuffer{
+		FeederConsumer: newFeederConsumerSafeOutputBuffer(decompressor),
+		decompressor:   decompressor,
+	}
+}
+
+func (c *decompressorSafeOutputBuffer) GzipHeader() (*common.GzipHeader, bool) {
+	return c.decompressor.GzipHeader()
+}
+
+func (c *decompressorSafeOutputBuffer) DetectedHeader() (common.HeaderType, bool) {
+	return c.decompressor.DetectedHeader()
+}
+
+func (c *decompressorSafeOutputBuffer) Reset(opts common.DecompressOptions) error {
+	return c.decompressor.Reset(opts)
+}
+
+func (c *decompressorSafeOutputBuffer) Prime(bits, value int) error {
+	return c.decompressor.Prime(bits, value)
+}
+
+func (c *decompressorSafeOutputBuffer) Remaining() []byte {
+	return c.decompressor.Remaining()
+}
+
+func (c *decompressorSafeOutputBuffer) GetDictionary() ([]byte, error) {
+	return c.decompressor.GetDictionary()
+}
+
+func (c *decompressorSafeOutputBuffer) ClearOptions() {
+	c.decompressor.ClearOptions()
+}
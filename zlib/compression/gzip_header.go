@@ -0,0 +1,39 @@
+package compression
+
+import (
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func toZGzipHeader(header *common.GzipHeader) (*capi.GzipHeader, error) {
+	mtime, err := header.EncodedModTime()
+	if err != nil {
+		return nil, err
+	}
+	return &capi.GzipHeader{
+		Text:    header.Text,
+		Time:    mtime,
+		OS:      int(header.EncodedOS()),
+		Extra:   header.Extra,
+		Name:    header.Name,
+		Comment: header.Comment,
+		HCRC:    header.HCRC,
+	}, nil
+}
+
+func fromZGzipHeader(zheader *capi.GzipHeader) *common.GzipHeader {
+	header := &common.GzipHeader{
+		Text:    zheader.Text,
+		OS:      byte(zheader.OS),
+		Extra:   zheader.Extra,
+		Name:    zheader.Name,
+		Comment: zheader.Comment,
+		HCRC:    zheader.HCRC,
+	}
+	if zheader.Time != 0 {
+		header.ModTime = time.Unix(int64(zheader.Time), 0)
+	}
+	return header
+}
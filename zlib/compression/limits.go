@@ -0,0 +1,93 @@
+package compression
+
+import "github.com/MeenaAlfons/go-zlib/zlib/common"
+
+const (
+	// ratioMinOutput is the size of the decompressed data from which the ratio limit is checked.
+	ratioMinOutput = 64 * 1024
+	// gzipMagic is the first byte of a gzip header.
+	gzipMagic = 0x1f
+	// zlibMethodDeflate is the compression method in the low bits of the first byte of a zlib header.
+	zlibMethodDeflate = 8
+)
+
+// windowOptions are the options used to compute the windowBits of inflateInit2.
+type windowOptions struct {
+	windowBits int
+	header     common.HeaderType
+}
+
+func (opts windowOptions) WindowBits() int {
+	return opts.windowBits
+}
+
+func (opts windowOptions) Header() common.HeaderType {
+	return opts.header
+}
+
+// decompressWindowBits returns the windowBits of inflateInit2 for opts.
+// The window is limited to MaxWindowBits so that inflate never allocates a larger window.
+// A windowBits of 0 is limited as well since inflate would use a window of 15 bits for a gzip stream.
+func decompressWindowBits(opts common.DecompressOptions) int {
+	windowBits := opts.WindowBits()
+	if maxWindowBits := opts.MaxWindowBits(); maxWindowBits > 0 && (windowBits == 0 || windowBits > maxWindowBits) {
+		windowBits = maxWindowBits
+	}
+	return zWindowBits(windowOptions{windowBits: windowBits, header: opts.Header()})
+}
+
+// limitOutput shortens outputBuffer so that inflate produces at most one byte past MaxOutputSize.
+// The extra byte tells a stream exceeding the limit apart from a stream ending right at the limit.
+func (c *decompressor) limitOutput(outputBuffer []byte) []byte {
+	if c.maxOutputSize <= 0 {
+		return outputBuffer
+	}
+	allowed := c.maxOutputSize - c.previousTotalOut - c.zstream.TotalOut() + 1
+	if int64(len(outputBuffer)) > allowed {
+		return outputBuffer[:allowed]
+	}
+	return outputBuffer
+}
+
+// checkHeaderWindow ends the stream with a LimitError when the zlib header of the member declares a window larger
+// than MaxWindowBits. The window is declared in the first byte of the header which is checked before inflate reads it.
+// A gzip header found with HeaderTypeAuto and an invalid zlib header are left to inflate.
+func (c *decompressor) checkHeaderWindow() error {
+	if !c.inspectHeader {
+		return nil
+	}
+	input := c.zstream.UnconsumedInput()
+	if len(input) == 0 {
+		return nil
+	}
+	c.inspectHeader = false
+
+	cmf := input[0]
+	if (c.header == common.HeaderTypeAuto && cmf == gzipMagic) || cmf&0x0f != zlibMethodDeflate {
+		return nil
+	}
+	windowBits := int(cmf>>4) + 8
+	if windowBits > c.maxWindowBits {
+		return c.endStream(&common.LimitError{Limit: common.LimitWindowBits, Value: float64(windowBits), Max: float64(c.maxWindowBits)})
+	}
+	return nil
+}
+
+// checkLimits ends the stream with a LimitError when the output exceeds MaxOutputSize or MaxRatio after a call to inflate.
+// have is the output produced by the call. The output past MaxOutputSize is dropped from the returned have.
+func (c *decompressor) checkLimits(have int) (int, error) {
+	totalOut := c.previousTotalOut + c.zstream.TotalOut()
+	if c.maxOutputSize > 0 && totalOut > c.maxOutputSize {
+		have -= int(totalOut - c.maxOutputSize)
+		return have, c.endStream(&common.LimitError{Limit: common.LimitOutputSize, Value: float64(totalOut), Max: float64(c.maxOutputSize)})
+	}
+
+	totalIn := c.previousTotalIn + c.zstream.TotalIn()
+	if c.maxRatio > 0 && totalOut > ratioMinOutput && totalIn > 0 {
+		ratio := float64(totalOut) / float64(totalIn)
+		if ratio > c.maxRatio {
+			return have, c.endStream(&common.LimitError{Limit: common.LimitRatio, Value: ratio, Max: c.maxRatio})
+		}
+	}
+	return have, nil
+}
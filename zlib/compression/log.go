@@ -0,0 +1,30 @@
+package compression
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/utils"
+)
+
+// logCall logs a call to Feed or Consume at debug level. input is the length of the input fed, or -1 for Consume.
+func logCall(logger *slog.Logger, msg string, zstream capi.ZStream, flush Flush, input, have int, hasMoreOutput bool, err error) {
+	logger = utils.DebugLogger(logger)
+	if logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("stream", fmt.Sprintf("%p", zstream)),
+		slog.String("flush", flush.String()),
+	}
+	if input >= 0 {
+		attrs = append(attrs, slog.Int("input", input))
+	}
+	attrs = append(attrs, slog.Int("have", have), slog.Bool("more_output", hasMoreOutput))
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+	}
+	logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
@@ -0,0 +1,87 @@
+package compression
+
+import (
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// observation reports the events of a stream to its observer. All methods do nothing when there is no observer.
+type observation struct {
+	observer  common.Observer
+	direction common.Direction
+}
+
+// zlibCall is a call into zlib in progress.
+type zlibCall struct {
+	availIn int
+	start   time.Time
+	done    func()
+}
+
+func (o observation) streamInit() {
+	if o.observer != nil {
+		o.observer.StreamInit(o.direction)
+	}
+}
+
+// begin is called right before calling into zlib with op.
+func (o observation) begin(zstream capi.ZStream, op capi.Op) zlibCall {
+	if o.observer == nil {
+		return zlibCall{}
+	}
+	call := zlibCall{
+		availIn: zstream.AvailIn(),
+		done:    o.observer.ZlibCall(o.direction, op),
+	}
+	call.start = time.Now()
+	return call
+}
+
+// end is called right after the call into zlib returns.
+func (o observation) end(zstream capi.ZStream, call zlibCall) common.Call {
+	if o.observer == nil {
+		return common.Call{}
+	}
+	duration := time.Since(call.start)
+	if call.done != nil {
+		call.done()
+	}
+	return common.Call{
+		Direction: o.direction,
+		BytesIn:   call.availIn - zstream.AvailIn(),
+		BytesOut:  zstream.ProducedOutput(),
+		Duration:  duration,
+	}
+}
+
+func (o observation) feed(call common.Call) {
+	if o.observer != nil {
+		o.observer.Feed(call)
+	}
+}
+
+func (o observation) consume(call common.Call) {
+	if o.observer != nil {
+		o.observer.Consume(call)
+	}
+}
+
+func (o observation) flush() {
+	if o.observer != nil {
+		o.observer.Flush(o.direction)
+	}
+}
+
+// streamEnd reports the end of the stream. stats is only called when the stream ended successfully.
+func (o observation) streamEnd(reason error, stats func() common.Stats) {
+	if o.observer == nil {
+		return
+	}
+	if reason != nil {
+		o.observer.Error(o.direction, reason)
+		return
+	}
+	o.observer.StreamEnd(o.direction, stats())
+}
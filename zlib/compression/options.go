@@ -9,8 +9,13 @@ type options interface {
 
 func zWindowBits(opts options) int {
 	windowBits := opts.WindowBits()
-	if opts.Header() == common.HeaderTypeRaw {
+	switch opts.Header() {
+	case common.HeaderTypeRaw:
 		windowBits = -windowBits
+	case common.HeaderTypeGzip:
+		windowBits += 16
+	case common.HeaderTypeAuto:
+		windowBits += 32
 	}
 	return windowBits
 }
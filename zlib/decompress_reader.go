@@ -10,6 +10,16 @@ import (
 
 // NewDecompressReader reads compressed data from target and decompresses it.
 // It returns a ReadCloser that reads decompressed data.
+// The returned value implements common.GzipHeaderGetter and common.HeaderDetector to report the header of the compressed data.
+// It also implements common.DecompressReaderResetter to be reused for another stream
+// and Primer to start decompressing a raw deflate stream in the middle of a byte.
+// DictionaryGetter returns the data decompressed recently, for example to build a restart point.
+// common.StatsReporter reports the sizes and checksum of the stream.
+// When target is a *bufio.Reader, the compressed data is read exactly up to the end of the stream
+// and the data after it is left in target. Otherwise, target may be read past the end of the stream.
+// The data read past the end of the stream is returned by Remaining.
+// Close must be called once done, also after the end of the stream, to release the memory allocated by zlib.
+// Otherwise, it is only released when the value is garbage collected.
 func NewDecompressReader(target io.Reader, opts common.DecompressOptions) (io.ReadCloser, error) {
 	zcompressor, err := compression.NewDecompressor(opts)
 	if err != nil {
@@ -17,13 +27,15 @@ func NewDecompressReader(target io.Reader, opts common.DecompressOptions) (io.Re
 	}
 
 	r := &decompressReader{
-		impl: feederio.NewFeederReader(target, zcompressor, opts.BufferSize()),
+		impl:         feederio.NewFeederReader(target, zcompressor, opts.BufferSize()),
+		decompressor: zcompressor,
 	}
 	return r, nil
 }
 
 type decompressReader struct {
-	impl io.ReadCloser
+	impl         feederio.FeederReader
+	decompressor compression.Decompressor
 }
 
 // Read reads decompressed data resulting from decompressing the data read from target.
@@ -31,7 +43,59 @@ func (r *decompressReader) Read(p []byte) (n int, err error) {
 	return r.impl.Read(p)
 }
 
-// Close method is unnecessary at this moment.
+// Close releases the zlib state. A Reset after Close needs to initialize it again.
+// If Close is not called, the zlib state is released when the reader is garbage collected.
 func (r *decompressReader) Close() error {
-	return r.impl.Close()
+	err := r.impl.Close()
+	closeErr := r.decompressor.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Reset discards the current state and makes the reader decompress data read from target with the given options.
+// The buffers are reused when the buffer size did not change.
+// The zlib state is reused when Close has not been called.
+func (r *decompressReader) Reset(target io.Reader, opts common.DecompressOptions) error {
+	err := r.decompressor.Reset(opts)
+	if err != nil {
+		return err
+	}
+	r.impl.Reset(target, opts.BufferSize())
+	return nil
+}
+
+// GzipHeader returns the gzip header read from the compressed data.
+// It returns false if the header has not been read yet or if the compressed data has no gzip header.
+func (r *decompressReader) GzipHeader() (*common.GzipHeader, bool) {
+	return r.decompressor.GzipHeader()
+}
+
+// DetectedHeader returns the header type of the compressed data.
+// With HeaderTypeAuto, it returns false until enough compressed data has been read to detect the header.
+func (r *decompressReader) DetectedHeader() (common.HeaderType, bool) {
+	return r.decompressor.DetectedHeader()
+}
+
+// Prime inserts the low bits of value in front of the compressed data.
+// It can only be used with common.HeaderTypeRaw and before the first Read.
+func (r *decompressReader) Prime(bits, value int) error {
+	return r.decompressor.Prime(bits, value)
+}
+
+// Remaining returns the data read from target after the end of the compressed stream.
+// It is nil until the stream ends. When target is a *bufio.Reader, this data is also left unread in target.
+func (r *decompressReader) Remaining() []byte {
+	return r.decompressor.Remaining()
+}
+
+// GetDictionary returns up to the last 32 KiB of decompressed data.
+func (r *decompressReader) GetDictionary() ([]byte, error) {
+	return r.decompressor.GetDictionary()
+}
+
+// Stats returns the statistics of the current stream.
+func (r *decompressReader) Stats() common.Stats {
+	return r.decompressor.Stats()
 }
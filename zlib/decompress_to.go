@@ -0,0 +1,70 @@
+package zlib
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+)
+
+// maxConsecutiveEmptyReads is the number of reads returning no data and no error after which DecompressTo
+// fails with io.ErrNoProgress, like bufio does.
+const maxConsecutiveEmptyReads = 100
+
+// DecompressTo decompresses the raw deflate data read from r and writes the decompressed data to w.
+// It uses zlib's inflateBack which decompresses the whole stream in a single call into C.
+// It only calls back into Go to read more input and to write each full window of output.
+// This makes it faster than NewDecompressReader and NewDecompressWriter for large data.
+// r may be read past the end of the deflate data.
+// It returns the number of bytes written to w.
+func DecompressTo(w io.Writer, r io.Reader) (int64, error) {
+	zstream := capi.NewZStream()
+	ret := zstream.InflateBackInit(15)
+	if ret != capi.Z_OK {
+		return 0, zstream.Error(capi.OpInit, ret)
+	}
+	defer zstream.InflateBackEnd()
+
+	var written int64
+	var readErr, writeErr error
+	in := func(buf []byte) int {
+		for i := 0; readErr == nil; i++ {
+			if i == maxConsecutiveEmptyReads {
+				readErr = io.ErrNoProgress
+				break
+			}
+			n, err := r.Read(buf)
+			readErr = err
+			if n > 0 {
+				return n
+			}
+		}
+		return 0
+	}
+	out := func(p []byte) bool {
+		n, err := w.Write(p)
+		written += int64(n)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		writeErr = err
+		return err == nil
+	}
+
+	ret = zstream.InflateBack(in, out)
+	switch ret {
+	case capi.Z_STREAM_END:
+		return written, nil
+	case capi.Z_BUF_ERROR:
+		// in or out stopped the decompression.
+		if writeErr != nil {
+			return written, writeErr
+		}
+		if readErr != io.EOF {
+			return written, readErr
+		}
+		return written, fmt.Errorf("zlib: the compressed data ended before the end of the deflate stream: %w", io.ErrUnexpectedEOF)
+	default:
+		return written, zstream.Error(capi.OpInflateBack, ret)
+	}
+}
@@ -10,6 +10,13 @@ import (
 
 // NewDecompressWriter writes decompressed data to target.
 // It returns a WriteFlushCloser which is used to write compressed data to be decompressed.
+// The returned value implements common.GzipHeaderGetter and common.HeaderDetector to report the header of the compressed data.
+// It also implements common.DecompressWriterResetter to be reused for another stream
+// and Primer to start decompressing a raw deflate stream in the middle of a byte.
+// DictionaryGetter returns the data decompressed recently, for example to build a restart point.
+// common.StatsReporter reports the sizes and checksum of the stream.
+// Close must be called once done, also after the end of the stream, to release the memory allocated by zlib.
+// Otherwise, it is only released when the value is garbage collected.
 func NewDecompressWriter(target io.Writer, opts common.DecompressOptions) (common.WriteFlushCloser, error) {
 	zcompressor, err := compression.NewDecompressor(opts)
 	if err != nil {
@@ -17,16 +24,20 @@ func NewDecompressWriter(target io.Writer, opts common.DecompressOptions) (commo
 	}
 
 	r := &decompressWriter{
-		impl: feederio.NewFeederWriter(target, zcompressor, opts.BufferSize()),
+		impl:         feederio.NewFeederWriter(target, zcompressor, opts.BufferSize()),
+		decompressor: zcompressor,
 	}
 	return r, nil
 }
 
 type decompressWriter struct {
-	impl common.WriteFlushCloser
+	impl         feederio.FeederWriter
+	decompressor compression.Decompressor
 }
 
 // Write writes compressed data which will be decompressed and written to target.
+// When the stream ends, Write returns io.EOF and the number of bytes of p up to the end of the stream.
+// The data after the end of the stream is not written.
 func (w *decompressWriter) Write(p []byte) (n int, err error) {
 	return w.impl.Write(p)
 }
@@ -38,6 +49,52 @@ func (w *decompressWriter) Flush() error {
 
 // Close method concludes the decompression process and flushes the remaining decompressed data to target.
 // Write and Flush methods can not be called after Close.
+// Close releases the zlib state. A Reset after Close needs to initialize it again.
 func (w *decompressWriter) Close() error {
-	return w.impl.Close()
+	err := w.impl.Close()
+	closeErr := w.decompressor.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Reset discards the current state and makes the writer write decompressed data to target with the given options.
+// The buffers are reused when the buffer size did not change.
+// The zlib state is reused when Close has not been called.
+func (w *decompressWriter) Reset(target io.Writer, opts common.DecompressOptions) error {
+	err := w.decompressor.Reset(opts)
+	if err != nil {
+		return err
+	}
+	w.impl.Reset(target, opts.BufferSize())
+	return nil
+}
+
+// GzipHeader returns the gzip header read from the compressed data.
+// It returns false if the header has not been read yet or if the compressed data has no gzip header.
+func (w *decompressWriter) GzipHeader() (*common.GzipHeader, bool) {
+	return w.decompressor.GzipHeader()
+}
+
+// DetectedHeader returns the header type of the compressed data.
+// With HeaderTypeAuto, it returns false until enough compressed data has been read to detect the header.
+func (w *decompressWriter) DetectedHeader() (common.HeaderType, bool) {
+	return w.decompressor.DetectedHeader()
+}
+
+// Prime inserts the low bits of value in front of the compressed data.
+// It can only be used with common.HeaderTypeRaw and before the first Write.
+func (w *decompressWriter) Prime(bits, value int) error {
+	return w.decompressor.Prime(bits, value)
+}
+
+// GetDictionary returns up to the last 32 KiB of decompressed data.
+func (w *decompressWriter) GetDictionary() ([]byte, error) {
+	return w.decompressor.GetDictionary()
+}
+
+// Stats returns the statistics of the current stream.
+func (w *decompressWriter) Stats() common.Stats {
+	return w.decompressor.Stats()
 }
@@ -0,0 +1,52 @@
+package dictionary
+
+import (
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// Gain is the result of compressing samples with and without a dictionary.
+type Gain struct {
+	// UncompressedSize is the total size of the samples.
+	UncompressedSize int64
+	// CompressedSize is the total size of the samples compressed one by one without a dictionary.
+	CompressedSize int64
+	// CompressedSizeWithDictionary is the total size of the samples compressed one by one with the dictionary.
+	CompressedSizeWithDictionary int64
+}
+
+// Ratio returns how many times smaller the compressed samples are with the dictionary than without it.
+// A ratio above 1 means that the dictionary helps.
+func (g Gain) Ratio() float64 {
+	if g.CompressedSizeWithDictionary == 0 {
+		return 0
+	}
+	return float64(g.CompressedSize) / float64(g.CompressedSizeWithDictionary)
+}
+
+// Measure compresses each sample on its own with opts, once without a dictionary and once with dictionary
+// set by WithInitialDictionary, and reports the total sizes. opts is not modified.
+// The samples should not be the ones the dictionary was trained on to get a fair measure.
+func Measure(dictionary []byte, samples [][]byte, opts common.CompressOptions) (Gain, error) {
+	var gain Gain
+	without := opts.Clone().WithInitialDictionary(nil)
+	with := opts.Clone().WithInitialDictionary(dictionary)
+	var buf []byte
+	for _, sample := range samples {
+		gain.UncompressedSize += int64(len(sample))
+
+		var err error
+		buf, err = zlib.Compress(buf[:0], sample, without)
+		if err != nil {
+			return Gain{}, err
+		}
+		gain.CompressedSize += int64(len(buf))
+
+		buf, err = zlib.Compress(buf[:0], sample, with)
+		if err != nil {
+			return Gain{}, err
+		}
+		gain.CompressedSizeWithDictionary += int64(len(buf))
+	}
+	return gain, nil
+}
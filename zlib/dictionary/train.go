@@ -0,0 +1,185 @@
+// Package dictionary builds preset dictionaries for deflate from sample data.
+//
+// A preset dictionary is data that deflate can refer to from the start of a stream as if it had been
+// compressed right before it. It helps most with small inputs that share content with each other,
+// like records of the same schema, which on their own are too short to find many repetitions.
+// A dictionary is used with WithInitialDictionary on both the compress and the decompress options.
+package dictionary
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+)
+
+// MaxSize is the largest useful dictionary size. deflate can only refer to the last 32 KiB of the dictionary.
+const MaxSize = capi.MaxWindowSize
+
+const (
+	// dmerSize is the length of the substrings that are counted across the samples.
+	// It is 8 so that a substring can be packed into a uint64.
+	dmerSize = 8
+	// segmentSize is the length of the pieces of samples that make up the dictionary.
+	segmentSize = 64
+)
+
+// segment is a piece of a sample selected for the dictionary.
+type segment struct {
+	data  []byte
+	score int
+}
+
+// Train builds a preset dictionary of at most maxSize bytes from samples.
+// maxSize is capped at MaxSize.
+//
+// The dictionary is made of segments of the samples containing the substrings repeated in the most samples.
+// Once a segment is selected, its substrings no longer count for the next segments to avoid redundancy.
+// deflate encodes short distances with fewer bits, so the segments are ordered with the most useful ones at the end.
+// It returns nil when the samples share no substrings.
+func Train(samples [][]byte, maxSize int) []byte {
+	maxSize = min(maxSize, MaxSize)
+	if maxSize <= 0 {
+		return nil
+	}
+
+	frequencies := dmerFrequencies(samples)
+
+	// The samples are split into epochs of about the same size and one segment is selected from each epoch
+	// in turn so that the dictionary covers the whole corpus and not only the part where the best segment is.
+	epochs := splitEpochs(samples, max(maxSize/segmentSize, 1))
+
+	var selected []segment
+	size := 0
+	for size < maxSize {
+		found := false
+		for _, epoch := range epochs {
+			if size >= maxSize {
+				break
+			}
+			best, ok := bestSegment(epoch, frequencies)
+			if !ok {
+				continue
+			}
+			for i := 0; i+dmerSize <= len(best.data); i++ {
+				delete(frequencies, dmer(best.data[i:]))
+			}
+			selected = append(selected, best)
+			size += len(best.data)
+			found = true
+		}
+		if !found {
+			break
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	// The segments with the lowest score come first so that the most useful ones are the closest to the data.
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].score < selected[j].score
+	})
+	dictionary := make([]byte, 0, size)
+	for _, s := range selected {
+		dictionary = append(dictionary, s.data...)
+	}
+	// The dictionary may exceed maxSize by the last segment. Its start is the least useful part.
+	if len(dictionary) > maxSize {
+		dictionary = dictionary[len(dictionary)-maxSize:]
+	}
+	return dictionary
+}
+
+// dmerFrequencies counts in how many samples each substring of length dmerSize appears.
+// Substrings appearing in a single sample are dropped since the dictionary can not help with them.
+func dmerFrequencies(samples [][]byte) map[uint64]int {
+	frequencies := make(map[uint64]int)
+	seen := make(map[uint64]struct{})
+	for _, sample := range samples {
+		clear(seen)
+		for i := 0; i+dmerSize <= len(sample); i++ {
+			d := dmer(sample[i:])
+			if _, ok := seen[d]; ok {
+				continue
+			}
+			seen[d] = struct{}{}
+			frequencies[d]++
+		}
+	}
+	for d, frequency := range frequencies {
+		if frequency < 2 {
+			delete(frequencies, d)
+		}
+	}
+	return frequencies
+}
+
+// splitEpochs splits the samples into at most count groups of consecutive samples of about the same total size.
+func splitEpochs(samples [][]byte, count int) [][][]byte {
+	total := 0
+	for _, sample := range samples {
+		total += len(sample)
+	}
+	epochSize := max(total/count, 1)
+
+	var epochs [][][]byte
+	var epoch [][]byte
+	epochLength := 0
+	for _, sample := range samples {
+		epoch = append(epoch, sample)
+		epochLength += len(sample)
+		if epochLength >= epochSize {
+			epochs = append(epochs, epoch)
+			epoch = nil
+			epochLength = 0
+		}
+	}
+	if len(epoch) > 0 {
+		epochs = append(epochs, epoch)
+	}
+	return epochs
+}
+
+// bestSegment returns the segment of the samples of an epoch with the highest score.
+// The score of a segment is the sum of the frequencies of the distinct substrings it contains.
+// It returns false when no segment has a positive score.
+func bestSegment(epoch [][]byte, frequencies map[uint64]int) (segment, bool) {
+	var best segment
+	// counts holds how many times each substring appears in the current window.
+	counts := make(map[uint64]int)
+	for _, sample := range epoch {
+		if len(sample) < dmerSize {
+			continue
+		}
+		clear(counts)
+		score := 0
+		// The window holds the substrings starting in sample[start:end] which make up sample[start:end+dmerSize-1].
+		windowDmers := min(segmentSize, len(sample)) - dmerSize + 1
+		for end := 0; end+dmerSize <= len(sample); end++ {
+			d := dmer(sample[end:])
+			if counts[d] == 0 {
+				score += frequencies[d]
+			}
+			counts[d]++
+
+			start := end - windowDmers + 1
+			if start > 0 {
+				removed := dmer(sample[start-1:])
+				counts[removed]--
+				if counts[removed] == 0 {
+					score -= frequencies[removed]
+				}
+			}
+			if start >= 0 && score > best.score {
+				best = segment{data: sample[start : end+dmerSize], score: score}
+			}
+		}
+	}
+	return best, best.score > 0
+}
+
+// dmer packs the first dmerSize bytes of data into a uint64.
+func dmer(data []byte) uint64 {
+	return binary.LittleEndian.Uint64(data)
+}
@@ -0,0 +1,28 @@
+package zlib
+
+import (
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// ZlibError is the error returned when a zlib call fails.
+// It carries the return code, the operation and the message of zlib, for example "incorrect header check".
+type ZlibError = capi.ZlibError
+
+// Sentinel errors for the return codes of zlib. They can be matched with errors.Is against the errors
+// returned by this package, for example to tell corrupted data (ErrData) apart from a missing dictionary (ErrNeedDict).
+var (
+	ErrNeedDict = capi.ErrNeedDict
+	ErrErrno    = capi.ErrErrno
+	ErrStream   = capi.ErrStream
+	ErrData     = capi.ErrData
+	ErrMem      = capi.ErrMem
+	ErrBuf      = capi.ErrBuf
+	ErrVersion  = capi.ErrVersion
+)
+
+// LimitError is the error returned when decompression exceeds a limit of the decompress options.
+type LimitError = common.LimitError
+
+// ErrLimitExceeded is matched with errors.Is by a LimitError.
+var ErrLimitExceeded = common.ErrLimitExceeded
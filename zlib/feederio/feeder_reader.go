@@ -1,26 +1,32 @@
 package feederio
 
 import (
+	"bufio"
 	"io"
 
 	"github.com/MeenaAlfons/go-zlib/zlib/compression"
-	"github.com/MeenaAlfons/go-zlib/zlib/utils"
 )
 
-func NewFeederReader(reader io.Reader, feeder compression.FeederConsumer, bufferSize int) io.ReadCloser {
-	// When the stream arrives at the end of a buffer, its internal state would refer to a position past the end of the buffer.
-	// This results in error: "found pointer to free object".
-	// To avoid this case, we reserve one byte at the end of the buffer so that the final state will not point past the end of the buffer.
-	// The last byte of the input buffer is reserved for memory safety reasons.
-	// Note that the output buffer is protected by FeederConsumerSafeOutputBuffer.
-	inputBuffer := make([]byte, bufferSize)
-	inputBuffer = inputBuffer[:len(inputBuffer)-1]
+// FeederReader is a ReadCloser that reads input from a reader, feeds it to a FeederConsumer and returns the output.
+type FeederReader interface {
+	io.ReadCloser
+
+	// Reset makes the FeederReader read from reader while reusing its buffers when bufferSize did not change.
+	// The FeederConsumer is not reset and needs to be reset separately.
+	Reset(reader io.Reader, bufferSize int)
+}
+
+// remainder is implemented by a FeederConsumer that can end before consuming all of its input.
+type remainder interface {
+	Remaining() []byte
+}
 
-	return &feederReader{
-		reader:       reader,
-		feeder:       feeder,
-		zInputBuffer: inputBuffer,
+func NewFeederReader(reader io.Reader, feeder compression.FeederConsumer, bufferSize int) FeederReader {
+	r := &feederReader{
+		feeder: feeder,
 	}
+	r.Reset(reader, bufferSize)
+	return r
 }
 
 type feederReader struct {
@@ -28,16 +34,51 @@ type feederReader struct {
 	reader io.Reader
 
 	zInputBuffer []byte
+
+	// buffered is set when reader is a *bufio.Reader. The input is then peeked and only discarded
+	// from buffered once it is consumed so that reading stops exactly at the end of the stream.
+	buffered *bufio.Reader
+	// peeked is the number of bytes peeked from buffered and fed but not yet discarded.
+	peeked int
+}
+
+func (r *feederReader) Reset(reader io.Reader, bufferSize int) {
+	r.reader = reader
+	r.buffered, _ = reader.(*bufio.Reader)
+	r.peeked = 0
+
+	if cap(r.zInputBuffer) == bufferSize {
+		return
+	}
+	// When the stream arrives at the end of a buffer, its internal state would refer to a position past the end of the buffer.
+	// This results in error: "found pointer to free object".
+	// To avoid this case, we reserve one byte at the end of the buffer so that the final state will not point past the end of the buffer.
+	// The last byte of the input buffer is reserved for memory safety reasons.
+	// Note that the output buffer is protected by FeederConsumerSafeOutputBuffer.
+	inputBuffer := make([]byte, bufferSize)
+	r.zInputBuffer = inputBuffer[:len(inputBuffer)-1]
 }
 
 func (r *feederReader) Read(p []byte) (n int, err error) {
 	if r.feeder.CanCallConsume() {
 		n, err = r.feeder.Consume(p)
-		return
+		return n, r.discardConsumed(err)
+	}
+
+	// Do not read input that would not be consumed after the end of the stream.
+	// The input read after the end of the stream would not be kept by Remaining.
+	if isDone, reason := r.feeder.IsDoneWithReason(); isDone {
+		if reason != nil {
+			return 0, reason
+		}
+		return 0, io.EOF
 	}
 
-	n, err = r.reader.Read(r.zInputBuffer)
-	utils.Debug("FeederReader.Read after reader.Read n:%d, err:%v", n, err)
+	if r.buffered != nil {
+		n, err = r.peek()
+	} else {
+		n, err = r.reader.Read(r.zInputBuffer)
+	}
 	if err != nil && err != io.EOF {
 		return n, err
 	}
@@ -48,12 +89,53 @@ func (r *feederReader) Read(p []byte) (n int, err error) {
 	}
 	if flush == compression.Finish || n > 0 {
 		// Only feed data with length > 0 or flush == true
-		utils.Debug("FeederReader.Read Calling feeder.Feed input: %p, n: %d, flush: %v", &r.zInputBuffer[0], n, flush)
-		return r.feeder.Feed(r.zInputBuffer[:n], flush, p)
+		n, err = r.feeder.Feed(r.zInputBuffer[:n], flush, p)
+		return n, r.discardConsumed(err)
 	}
 	return 0, nil
 }
 
+// peek reads the next input from buffered without consuming it.
+// The input peeked before is discarded since it has been fully consumed when more input is needed.
+// It only peeks what is already buffered unless the buffer is empty to avoid blocking on more input than needed.
+func (r *feederReader) peek() (int, error) {
+	if _, err := r.buffered.Discard(r.peeked); err != nil {
+		return 0, err
+	}
+	r.peeked = 0
+
+	if _, err := r.buffered.Peek(1); err != nil {
+		return 0, err
+	}
+	size := r.buffered.Buffered()
+	if size > len(r.zInputBuffer) {
+		size = len(r.zInputBuffer)
+	}
+	input, err := r.buffered.Peek(size)
+	if err != nil {
+		return 0, err
+	}
+	r.peeked = copy(r.zInputBuffer, input)
+	return r.peeked, nil
+}
+
+// discardConsumed discards the consumed input from buffered once the feeder has ended.
+// The input that is remaining after the end of the stream is left in buffered.
+func (r *feederReader) discardConsumed(err error) error {
+	if r.buffered == nil || err != io.EOF {
+		return err
+	}
+	consumed := r.peeked
+	if feeder, ok := r.feeder.(remainder); ok {
+		consumed -= len(feeder.Remaining())
+	}
+	r.peeked = 0
+	if _, discardErr := r.buffered.Discard(consumed); discardErr != nil {
+		return discardErr
+	}
+	return err
+}
+
 func (r *feederReader) Close() error {
 	return nil
 }
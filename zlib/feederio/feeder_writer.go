@@ -6,24 +6,26 @@ import (
 
 	"github.com/MeenaAlfons/go-zlib/zlib/common"
 	"github.com/MeenaAlfons/go-zlib/zlib/compression"
-	"github.com/MeenaAlfons/go-zlib/zlib/utils"
 )
 
-func NewFeederWriter(writer io.Writer, feeder compression.FeederConsumer, bufferSize int) common.WriteFlushCloser {
-	// When the stream arrives at the end of a buffer, its internal state would refer to a position past the end of the buffer.
-	// This results in error: "found pointer to free object".
-	// To avoid this case, we reserve one byte at the end of the buffer so that the final state will not point past the end of the buffer.
-	// The last byte of the input buffer is reserved for memory safety reasons.
-	// Note that the output buffer is protected by FeederConsumerSafeOutputBuffer.
-	inputBuffer := make([]byte, bufferSize)
-	inputBuffer = inputBuffer[:len(inputBuffer)-1]
+// FeederWriter is a WriteFlushCloser that feeds the written input to a FeederConsumer and writes the output to a writer.
+type FeederWriter interface {
+	common.WriteFlushCloser
 
-	return &feederWriter{
-		feeder:        feeder,
-		writer:        writer,
-		zInputBuffer:  inputBuffer,
-		zOutputBuffer: make([]byte, bufferSize),
+	// FlushWith is similar to Flush but uses the given flush mode instead of SyncFlush.
+	FlushWith(flush compression.Flush) error
+
+	// Reset makes the FeederWriter write to writer while reusing its buffers when bufferSize did not change.
+	// The FeederConsumer is not reset and needs to be reset separately.
+	Reset(writer io.Writer, bufferSize int)
+}
+
+func NewFeederWriter(writer io.Writer, feeder compression.FeederConsumer, bufferSize int) FeederWriter {
+	w := &feederWriter{
+		feeder: feeder,
 	}
+	w.Reset(writer, bufferSize)
+	return w
 }
 
 type feederWriter struct {
@@ -34,6 +36,22 @@ type feederWriter struct {
 	zOutputBuffer []byte
 }
 
+func (r *feederWriter) Reset(writer io.Writer, bufferSize int) {
+	r.writer = writer
+
+	if len(r.zOutputBuffer) == bufferSize {
+		return
+	}
+	// When the stream arrives at the end of a buffer, its internal state would refer to a position past the end of the buffer.
+	// This results in error: "found pointer to free object".
+	// To avoid this case, we reserve one byte at the end of the buffer so that the final state will not point past the end of the buffer.
+	// The last byte of the input buffer is reserved for memory safety reasons.
+	// Note that the output buffer is protected by FeederConsumerSafeOutputBuffer.
+	inputBuffer := make([]byte, bufferSize)
+	r.zInputBuffer = inputBuffer[:len(inputBuffer)-1]
+	r.zOutputBuffer = make([]byte, bufferSize)
+}
+
 func (r *feederWriter) writeSome(p []byte) (int, error) {
 	newP := p
 	// newP := make([]byte, len(p))
@@ -43,7 +61,6 @@ func (r *feederWriter) writeSome(p []byte) (int, error) {
 	// and return it as the number of bytes written
 
 	flush := compression.NoFlush
-	utils.Debug("FeederWriter.Read Calling feeder.Feed input: %p, n: %d, flush: %v", &newP[0], len(newP), flush)
 	n1, err1 := r.feeder.Feed(newP, flush, r.zOutputBuffer)
 	if err1 != nil && err1 != io.EOF {
 		return len(p), err1
@@ -89,6 +106,12 @@ func (r *feederWriter) Write(p []byte) (int, error) {
 		n := copy(r.zInputBuffer, p[inputIndex:])
 		inputIndex += n
 		_, err := r.writeSome(r.zInputBuffer[:n])
+		if err == io.EOF {
+			// The stream ended before the end of the input. The input after the end of the stream is not written.
+			if feeder, ok := r.feeder.(remainder); ok {
+				inputIndex -= len(feeder.Remaining())
+			}
+		}
 		if err != nil {
 			return inputIndex, err
 		}
@@ -97,6 +120,14 @@ func (r *feederWriter) Write(p []byte) (int, error) {
 }
 
 func (r *feederWriter) Flush() error {
+	return r.FlushWith(compression.SyncFlush)
+}
+
+func (r *feederWriter) FlushWith(flush compression.Flush) error {
+	if flush == compression.NoFlush || flush == compression.Finish {
+		return fmt.Errorf("zlib: flush mode %d is not a flush. Use Write or Close instead", flush)
+	}
+
 	if isDone, reason := r.feeder.IsDoneWithReason(); isDone {
 		if reason != nil {
 			return reason
@@ -105,7 +136,6 @@ func (r *feederWriter) Flush() error {
 		return nil
 	}
 
-	flush := compression.SyncFlush
 	n1, err1 := r.feeder.Feed(nil, flush, r.zOutputBuffer)
 	if err1 != nil && err1 != io.EOF {
 		return err1
@@ -0,0 +1,194 @@
+// Package index provides random access to zlib, gzip and raw deflate streams.
+//
+// An Index is built by decompressing the whole stream once and recording checkpoints
+// every span bytes of decompressed data. Each checkpoint holds what is needed to resume
+// decompression from its position: the bit offset in the compressed data and the
+// 32 KiB window of decompressed data preceding it. A Reader then serves reads in the
+// middle of the stream by decompressing from the closest checkpoint only.
+//
+// This is the approach of zran.c from the zlib examples.
+package index
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// DefaultSpan is the default distance between checkpoints in bytes of decompressed data.
+const DefaultSpan = 1 << 20
+
+// Index holds the checkpoints of a compressed stream.
+type Index struct {
+	// Header is the header of the compressed stream.
+	// It is never HeaderTypeAuto. The header is detected when the index is built with HeaderTypeAuto.
+	Header common.HeaderType
+	// Span is the minimum distance between checkpoints in bytes of decompressed data.
+	Span int64
+	// CompressedSize is the offset of the end of the deflate data in the compressed stream.
+	// It excludes the zlib or gzip trailer.
+	CompressedSize int64
+	// UncompressedSize is the size of the decompressed data.
+	UncompressedSize int64
+	// Points are the checkpoints sorted by their offset in the decompressed data.
+	// The first one is always at the start of the deflate data.
+	Points []Point
+}
+
+// Point is a position in a compressed stream where decompression can start.
+type Point struct {
+	// Out is the offset in the decompressed data.
+	Out int64
+	// In is the offset of the first full byte of compressed data after the checkpoint.
+	In int64
+	// Bits is the number of bits of the byte before In that belong to the compressed data after the checkpoint.
+	// It is between 0 and 7.
+	Bits int
+	// Window is the decompressed data preceding the checkpoint. It is at most 32 KiB.
+	Window []byte
+}
+
+// Build decompresses the stream read from r and returns its index with checkpoints every span bytes
+// of decompressed data. header can be HeaderTypeAuto to detect a zlib or gzip header.
+// Only the first stream is indexed. Anything after its end is not read.
+func Build(r io.Reader, header common.HeaderType, span int64) (*Index, error) {
+	if span <= 0 {
+		return nil, fmt.Errorf("zlib: index span must be positive, got %d", span)
+	}
+
+	zstream := capi.NewZStream()
+	ret := zstream.InflateInit2(windowBits(header))
+	if ret != capi.Z_OK {
+		return nil, zstream.Error(capi.OpInit, ret)
+	}
+	defer zstream.InflateEnd()
+
+	if header == common.HeaderTypeAuto {
+		// The gzip header is requested to detect whether the stream has a gzip or zlib header.
+		ret = zstream.InflateGetHeader()
+		if ret != capi.Z_OK {
+			return nil, zstream.Error(capi.OpGetHeader, ret)
+		}
+	}
+
+	idx := &Index{
+		Header: header,
+		Span:   span,
+	}
+	if header == common.HeaderTypeRaw {
+		// inflate does not stop at the start of a raw stream since there is no header.
+		idx.Points = append(idx.Points, Point{})
+	}
+
+	// One more byte of capacity is reserved for memory safety reasons.
+	input := make([]byte, inputBufferSize+1)[:inputBufferSize]
+	output := make([]byte, capi.MaxWindowSize+1)[:capi.MaxWindowSize]
+	last := int64(0)
+	eof := false
+	for {
+		if zstream.AvailIn() == 0 && !eof {
+			n, err := r.Read(input)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			// inflate may still need to be called without input to get to the end of a raw stream.
+			eof = err == io.EOF
+			zstream.SetInput(input[:n])
+		}
+
+		// Z_BLOCK makes inflate return at the end of the header and at the end of each deflate block.
+		zstream.SetOutput(output)
+		ret = zstream.Inflate(capi.Z_BLOCK)
+		switch ret {
+		case capi.Z_OK:
+		case capi.Z_BUF_ERROR:
+			if eof {
+				return nil, fmt.Errorf("zlib: the compressed stream ended before the end of the deflate data: %w", io.ErrUnexpectedEOF)
+			}
+		case capi.Z_STREAM_END:
+			return idx.finish(zstream), nil
+		default:
+			return nil, zstream.Error(capi.OpInflate, ret)
+		}
+
+		// Add a checkpoint at the end of a block unless it is the last block.
+		dataType := zstream.DataType()
+		if dataType&128 == 0 || dataType&64 != 0 {
+			continue
+		}
+		out := zstream.TotalOut()
+		if len(idx.Points) > 0 && out-last <= span {
+			continue
+		}
+		window, ret := zstream.InflateGetDictionary()
+		if ret != capi.Z_OK {
+			return nil, zstream.Error(capi.OpGetDictionary, ret)
+		}
+		idx.Points = append(idx.Points, Point{
+			Out:    out,
+			In:     zstream.TotalIn(),
+			Bits:   dataType & 7,
+			Window: window,
+		})
+		last = out
+	}
+}
+
+// finish records the sizes of the stream after inflate returned Z_STREAM_END.
+func (idx *Index) finish(zstream capi.ZStream) *Index {
+	if idx.Header == common.HeaderTypeAuto {
+		idx.Header = common.HeaderTypeZlib
+		if zstream.GzipHeaderDone() == 1 {
+			idx.Header = common.HeaderTypeGzip
+		}
+	}
+	idx.UncompressedSize = zstream.TotalOut()
+	idx.CompressedSize = zstream.TotalIn() - trailerSize(idx.Header)
+	return idx
+}
+
+// point returns the last checkpoint at or before offset in the decompressed data.
+// It returns an error when there is no such checkpoint, which happens when the first one is not at offset 0.
+func (idx *Index) point(offset int64) (Point, error) {
+	i := sort.Search(len(idx.Points), func(i int) bool {
+		return idx.Points[i].Out > offset
+	})
+	if i == 0 {
+		return Point{}, errNoCheckpoint
+	}
+	return idx.Points[i-1], nil
+}
+
+// errNoCheckpoint is returned when an index has no checkpoint at the start of the deflate data.
+var errNoCheckpoint = errors.New("zlib: the index has no checkpoint at offset 0")
+
+// inputBufferSize is the size of the buffer used to read the compressed stream.
+const inputBufferSize = 1 << 16
+
+func windowBits(header common.HeaderType) int {
+	switch header {
+	case common.HeaderTypeRaw:
+		return -15
+	case common.HeaderTypeGzip:
+		return 15 + 16
+	case common.HeaderTypeAuto:
+		return 15 + 32
+	default:
+		return 15
+	}
+}
+
+func trailerSize(header common.HeaderType) int64 {
+	switch header {
+	case common.HeaderTypeZlib:
+		return 4
+	case common.HeaderTypeGzip:
+		return 8
+	default:
+		return 0
+	}
+}
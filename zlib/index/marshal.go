@@ -0,0 +1,116 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// The serialized index starts with magic followed by the format version.
+// The fields follow as unsigned varints in the order they are declared in Index and Point.
+// Each window is preceded by its length.
+var magic = []byte("GZIDX")
+
+const formatVersion = 1
+
+var errInvalidIndex = errors.New("zlib: invalid serialized index")
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (idx *Index) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(magic)
+	buf.WriteByte(formatVersion)
+	putUvarint(&buf, uint64(idx.Header))
+	putUvarint(&buf, uint64(idx.Span))
+	putUvarint(&buf, uint64(idx.CompressedSize))
+	putUvarint(&buf, uint64(idx.UncompressedSize))
+	putUvarint(&buf, uint64(len(idx.Points)))
+	for _, point := range idx.Points {
+		putUvarint(&buf, uint64(point.Out))
+		putUvarint(&buf, uint64(point.In))
+		putUvarint(&buf, uint64(point.Bits))
+		putUvarint(&buf, uint64(len(point.Window)))
+		buf.Write(point.Window)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (idx *Index) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	prefix := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, prefix); err != nil || !bytes.Equal(prefix[:len(magic)], magic) {
+		return errInvalidIndex
+	}
+	if prefix[len(magic)] != formatVersion {
+		return fmt.Errorf("zlib: unsupported serialized index version %d", prefix[len(magic)])
+	}
+
+	var fields [5]uint64
+	for i := range fields {
+		field, err := binary.ReadUvarint(r)
+		if err != nil {
+			return errInvalidIndex
+		}
+		fields[i] = field
+	}
+	header := common.HeaderType(fields[0])
+	if header != common.HeaderTypeZlib && header != common.HeaderTypeGzip && header != common.HeaderTypeRaw {
+		return errInvalidIndex
+	}
+	count := fields[4]
+	if count == 0 || count > uint64(r.Len()) {
+		return errInvalidIndex
+	}
+
+	points := make([]Point, count)
+	for i := range points {
+		var pointFields [4]uint64
+		for j := range pointFields {
+			field, err := binary.ReadUvarint(r)
+			if err != nil {
+				return errInvalidIndex
+			}
+			pointFields[j] = field
+		}
+		if pointFields[2] > 7 || pointFields[3] > capi.MaxWindowSize || pointFields[3] > uint64(r.Len()) {
+			return errInvalidIndex
+		}
+		window := make([]byte, pointFields[3])
+		if _, err := io.ReadFull(r, window); err != nil {
+			return errInvalidIndex
+		}
+		if i > 0 && int64(pointFields[0]) <= points[i-1].Out {
+			return errInvalidIndex
+		}
+		points[i] = Point{
+			Out:    int64(pointFields[0]),
+			In:     int64(pointFields[1]),
+			Bits:   int(pointFields[2]),
+			Window: window,
+		}
+	}
+	if r.Len() != 0 || points[0].Out != 0 {
+		return errInvalidIndex
+	}
+
+	*idx = Index{
+		Header:           header,
+		Span:             int64(fields[1]),
+		CompressedSize:   int64(fields[2]),
+		UncompressedSize: int64(fields[3]),
+		Points:           points,
+	}
+	return nil
+}
+
+func putUvarint(buf *bytes.Buffer, x uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	buf.Write(b[:n])
+}
@@ -0,0 +1,162 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// NewReader returns a Reader of the decompressed data of the compressed stream read from r.
+// idx must be the index of that compressed stream.
+func NewReader(r io.ReaderAt, idx *Index) *Reader {
+	return &Reader{
+		r:     r,
+		index: idx,
+	}
+}
+
+// Reader reads the decompressed data of an indexed compressed stream.
+// It implements io.ReaderAt and io.ReadSeeker.
+// Each read decompresses from the closest checkpoint at or before the read offset.
+// Sequential calls to Read continue decompressing where the previous call stopped.
+// ReadAt is safe for concurrent use. Read and Seek are not.
+type Reader struct {
+	r     io.ReaderAt
+	index *Index
+
+	// offset is the current offset of Read and Seek in the decompressed data.
+	offset int64
+
+	// current is the decompress reader used by Read. It is positioned at offset when it is not nil.
+	current io.ReadCloser
+}
+
+// ReadAt reads len(p) bytes of decompressed data starting at offset off.
+func (x *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("zlib: negative offset")
+	}
+	if off >= x.index.UncompressedSize {
+		return 0, io.EOF
+	}
+
+	r, err := x.decompressReaderAt(off)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	n, err := io.ReadFull(r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Read reads decompressed data starting at the current offset.
+func (x *Reader) Read(p []byte) (int, error) {
+	if x.offset >= x.index.UncompressedSize {
+		return 0, io.EOF
+	}
+
+	if x.current == nil {
+		r, err := x.decompressReaderAt(x.offset)
+		if err != nil {
+			return 0, err
+		}
+		x.current = r
+	}
+
+	n, err := x.current.Read(p)
+	x.offset += int64(n)
+	if err == io.EOF && x.offset < x.index.UncompressedSize {
+		err = fmt.Errorf("zlib: the compressed stream ended before the size recorded in the index: %w", io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
+
+// Seek sets the offset for the next Read in the decompressed data.
+func (x *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += x.offset
+	case io.SeekEnd:
+		offset += x.index.UncompressedSize
+	default:
+		return 0, errors.New("zlib: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("zlib: negative offset")
+	}
+
+	if offset != x.offset {
+		x.closeCurrent()
+		x.offset = offset
+	}
+	return offset, nil
+}
+
+// Close releases the resources used by Read.
+func (x *Reader) Close() error {
+	x.closeCurrent()
+	return nil
+}
+
+func (x *Reader) closeCurrent() {
+	if x.current != nil {
+		x.current.Close()
+		x.current = nil
+	}
+}
+
+// decompressReaderAt returns a reader of the decompressed data starting at offset.
+// It starts decompressing raw deflate data from the closest checkpoint using the checkpoint
+// window as the dictionary and skips the decompressed data up to offset.
+func (x *Reader) decompressReaderAt(offset int64) (io.ReadCloser, error) {
+	point, err := x.index.point(offset)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := common.DefaultDecompressOptions().
+		WithHeader(common.HeaderTypeRaw).
+		WithBufferSize(readerBufferSize)
+	if len(point.Window) > 0 {
+		opts.WithInitialDictionary(point.Window)
+	}
+	section := io.NewSectionReader(x.r, point.In, x.index.CompressedSize-point.In)
+	r, err := zlib.NewDecompressReader(section, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if point.Bits > 0 {
+		// The checkpoint starts in the middle of the byte before In.
+		var b [1]byte
+		if _, err := x.r.ReadAt(b[:], point.In-1); err != nil {
+			r.Close()
+			return nil, err
+		}
+		err := r.(zlib.Primer).Prime(point.Bits, int(b[0])>>(8-point.Bits))
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+
+	if _, err := io.CopyN(io.Discard, r, offset-point.Out); err != nil {
+		r.Close()
+		if err == io.EOF {
+			err = fmt.Errorf("zlib: the compressed stream ended before the offset: %w", io.ErrUnexpectedEOF)
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// readerBufferSize is the buffer size of the decompress readers used by Reader.
+const readerBufferSize = 1 << 16
@@ -0,0 +1,16 @@
+package zlib
+
+import (
+	"log/slog"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/utils"
+)
+
+// SetLogger sets the global logger used by the streams that do not have a logger set with WithLogger.
+// It can be called at any time and applies to the streams already created.
+// Debug messages are logged for each call to zlib and each call to Feed and Consume, so the logger
+// is only consulted when its handler is enabled for slog.LevelDebug. nil disables logging, which is the default.
+// When built with the debug tag, the default logger writes debug messages to stderr.
+func SetLogger(logger *slog.Logger) {
+	utils.SetLogger(logger)
+}
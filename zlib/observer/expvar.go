@@ -0,0 +1,125 @@
+// Package observer provides ready to use implementations of common.Observer.
+package observer
+
+import (
+	"context"
+	"expvar"
+	"runtime/trace"
+	"sync"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// Expvar is an observer that publishes counters with expvar and annotates the calls into zlib
+// with runtime/trace regions while tracing is enabled.
+//
+// The counters are published in a map with one nested map per direction, "compress" and "decompress", holding:
+//   - streams: the number of streams initialized or reset.
+//   - stream_ends: the number of streams that ended successfully.
+//   - errors: the number of streams that ended because of an error.
+//   - calls: the number of calls into zlib from Feed and Consume.
+//   - bytes_in and bytes_out: the number of bytes consumed and produced by zlib.
+//   - zlib_ns: the time spent in zlib in nanoseconds.
+//   - flushes: the number of flushes, including the final one.
+//
+// The regions are named after the zlib operation, for example "zlib.deflate".
+// An Expvar can be shared by any number of streams.
+type Expvar struct {
+	common.NopObserver
+	vars       *expvar.Map
+	directions [2]directionVars
+}
+
+// Make sure that Expvar implements Observer
+var _ common.Observer = (*Expvar)(nil)
+
+type directionVars struct {
+	streams    expvar.Int
+	streamEnds expvar.Int
+	errors     expvar.Int
+	calls      expvar.Int
+	bytesIn    expvar.Int
+	bytesOut   expvar.Int
+	zlibNs     expvar.Int
+	flushes    expvar.Int
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultExpvar *Expvar
+)
+
+// Default returns the library-wide Expvar published under the name "zlib".
+// It is created on the first call.
+func Default() *Expvar {
+	defaultOnce.Do(func() {
+		defaultExpvar = NewExpvar("zlib")
+	})
+	return defaultExpvar
+}
+
+// NewExpvar creates an Expvar publishing its counters under name.
+// Like expvar.Publish, it panics if name is already published.
+func NewExpvar(name string) *Expvar {
+	e := &Expvar{}
+	e.vars = expvar.NewMap(name)
+	for _, direction := range []common.Direction{common.DirectionCompress, common.DirectionDecompress} {
+		d := &e.directions[direction]
+		m := new(expvar.Map)
+		m.Set("streams", &d.streams)
+		m.Set("stream_ends", &d.streamEnds)
+		m.Set("errors", &d.errors)
+		m.Set("calls", &d.calls)
+		m.Set("bytes_in", &d.bytesIn)
+		m.Set("bytes_out", &d.bytesOut)
+		m.Set("zlib_ns", &d.zlibNs)
+		m.Set("flushes", &d.flushes)
+		e.vars.Set(direction.String(), m)
+	}
+	return e
+}
+
+// Vars returns the map holding the counters.
+func (e *Expvar) Vars() *expvar.Map {
+	return e.vars
+}
+
+func (e *Expvar) StreamInit(direction common.Direction) {
+	e.directions[direction].streams.Add(1)
+}
+
+func (e *Expvar) ZlibCall(direction common.Direction, op capi.Op) func() {
+	if !trace.IsEnabled() {
+		return nil
+	}
+	return trace.StartRegion(context.Background(), "zlib."+string(op)).End
+}
+
+func (e *Expvar) Feed(call common.Call) {
+	e.addCall(call)
+}
+
+func (e *Expvar) Consume(call common.Call) {
+	e.addCall(call)
+}
+
+func (e *Expvar) addCall(call common.Call) {
+	d := &e.directions[call.Direction]
+	d.calls.Add(1)
+	d.bytesIn.Add(int64(call.BytesIn))
+	d.bytesOut.Add(int64(call.BytesOut))
+	d.zlibNs.Add(int64(call.Duration))
+}
+
+func (e *Expvar) Flush(direction common.Direction) {
+	e.directions[direction].flushes.Add(1)
+}
+
+func (e *Expvar) StreamEnd(direction common.Direction, stats common.Stats) {
+	e.directions[direction].streamEnds.Add(1)
+}
+
+func (e *Expvar) Error(direction common.Direction, err error) {
+	e.directions[direction].errors.Add(1)
+}
@@ -0,0 +1,221 @@
+package zlib
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+// uncompressMinSize is the initial size of the output of Uncompress when dst has no spare capacity.
+const uncompressMinSize = 1024
+
+// contextSliceSize is the largest input of Compress and output of Uncompress given to zlib in a single call
+// when the options have a context, so that the context is checked every few milliseconds.
+const contextSliceSize = 256 * 1024
+
+// scratchSize is the largest input given to zlib in a single call when src has no spare capacity.
+// zlib needs at least one byte of spare capacity after its input (see compression.Compressor.Feed).
+// Such src is copied in slices to a scratch buffer whose last byte is reserved.
+const scratchSize = 64 * 1024
+
+// Compress compresses src in a single call and appends the compressed data to dst.
+// It returns the extended buffer. dst can be nil.
+// The output is preallocated to the upper bound of deflateBound for the options so that zlib is called only once.
+// When the options have a context, the input is fed in slices of contextSliceSize and the context is checked before each of them.
+// When src has no spare capacity, it is copied to a scratch buffer in slices of scratchSize.
+// When the options match those of zlib's compress2, which are a zlib header, windowBits 15, memory level 8,
+// the default strategy and no dictionary, tuning, observer, logger or context, compress2 is used directly.
+func Compress(dst, src []byte, opts common.CompressOptions) ([]byte, error) {
+	if isCompress2Options(opts) {
+		dst = slices.Grow(dst, capi.CompressBound(len(src)))
+		n, ret := capi.Compress2(dst[len(dst):cap(dst)], src, opts.Level())
+		if ret != capi.Z_OK {
+			return dst, capi.ZErrorOp(capi.OpCompress, ret)
+		}
+		return dst[:len(dst)+n], nil
+	}
+
+	compressor, err := compression.NewCompressor(opts)
+	if err != nil {
+		return dst, err
+	}
+	defer compressor.Close()
+
+	bound, err := compressor.Bound(len(src))
+	if err != nil {
+		return dst, err
+	}
+	// The output buffer has one extra byte which is reserved by the compressor for memory safety reasons.
+	dst = slices.Grow(dst, bound+1)
+	sliceSize := len(src)
+	if opts.Context() != nil {
+		sliceSize = contextSliceSize
+	}
+	scratch, sliceSize := newScratch(src, sliceSize)
+	for {
+		input, flush := src, compression.Finish
+		if len(input) > sliceSize {
+			input, flush = src[:sliceSize], compression.NoFlush
+		}
+		src = src[len(input):]
+		input = scratchInput(scratch, input)
+		var n int
+		n, err = compressor.Feed(input, flush, dst[len(dst):cap(dst)])
+		dst = dst[:len(dst)+n]
+		// The bound guarantees that the whole output fits. Consume is only called in case it does not.
+		for err == nil && compressor.CanCallConsume() {
+			dst = slices.Grow(dst, bound+1)
+			n, err = compressor.Consume(dst[len(dst):cap(dst)])
+			dst = dst[:len(dst)+n]
+		}
+		if err != nil || flush == compression.Finish {
+			break
+		}
+	}
+	if err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("zlib: compression did not end after all the input has been fed")
+		}
+		return dst, err
+	}
+	return dst, nil
+}
+
+// Uncompress decompresses the compressed data in src in a single call and appends the decompressed data to dst.
+// It returns the extended buffer and the number of bytes of src that were consumed.
+// The decompression ends at the end of the compressed stream, so src may contain more data after it.
+// The spare capacity of dst is used first. When it is not enough, the output is grown and decompression continues.
+// When the options have a context, the output is produced in slices of contextSliceSize and the context is checked before each of them.
+// When src has no spare capacity, it is copied to a scratch buffer in slices of scratchSize.
+// When the options match those of zlib's uncompress2, which are a zlib header, windowBits 15 and no dictionary,
+// resolver, recovery, member handling, observer, logger, context or limits, uncompress2 is used directly.
+func Uncompress(dst, src []byte, opts common.DecompressOptions) ([]byte, int, error) {
+	if isUncompress2Options(opts) {
+		return uncompress2(dst, src)
+	}
+
+	decompressor, err := compression.NewDecompressor(opts)
+	if err != nil {
+		return dst, 0, err
+	}
+	defer decompressor.Close()
+
+	outputSize := math.MaxInt
+	if opts.Context() != nil {
+		outputSize = contextSliceSize
+	}
+	scratch, sliceSize := newScratch(src, len(src))
+	// The output buffer has one extra byte which is reserved by the decompressor for memory safety reasons.
+	dst = growUncompressOutput(dst, len(src))
+	fed := 0
+	for {
+		input, flush := src[fed:], compression.Finish
+		if len(input) > sliceSize {
+			input, flush = input[:sliceSize], compression.NoFlush
+		}
+		fed += len(input)
+		input = scratchInput(scratch, input)
+		var n int
+		n, err = decompressor.Feed(input, flush, spare(dst, outputSize))
+		dst = dst[:len(dst)+n]
+		for err == nil && decompressor.CanCallConsume() {
+			dst = growUncompressOutput(dst, len(dst))
+			n, err = decompressor.Consume(spare(dst, outputSize))
+			dst = dst[:len(dst)+n]
+		}
+		if err != nil || flush == compression.Finish {
+			break
+		}
+	}
+	if err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("zlib: the compressed data ended before the end of the stream: %w", io.ErrUnexpectedEOF)
+		}
+		return dst, 0, err
+	}
+	return dst, fed - len(decompressor.Remaining()), nil
+}
+
+// newScratch returns the scratch buffer through which src is fed when it has no spare capacity, or nil otherwise.
+// It also returns sliceSize limited to scratchSize when a scratch buffer is needed.
+func newScratch(src []byte, sliceSize int) ([]byte, int) {
+	if cap(src) > len(src) {
+		return nil, sliceSize
+	}
+	sliceSize = min(sliceSize, scratchSize)
+	// The last byte of the scratch buffer is reserved so that the input always has spare capacity.
+	return make([]byte, min(len(src), sliceSize)+1), sliceSize
+}
+
+// scratchInput copies input to scratch when scratch is not nil so that it has spare capacity.
+func scratchInput(scratch, input []byte) []byte {
+	if scratch == nil {
+		return input
+	}
+	return scratch[:copy(scratch[:len(scratch)-1], input)]
+}
+
+// uncompress2 decompresses src with zlib's uncompress2.
+// uncompress2 needs the whole output to fit in dst. When it does not, dst is grown and decompression starts over.
+func uncompress2(dst, src []byte) ([]byte, int, error) {
+	dst = growUncompressOutput(dst, len(src))
+	for {
+		n, consumed, ret := capi.Uncompress2(dst[len(dst):cap(dst)], src)
+		switch ret {
+		case capi.Z_OK:
+			return dst[:len(dst)+n], consumed, nil
+		case capi.Z_BUF_ERROR:
+			dst = slices.Grow(dst, 2*(cap(dst)-len(dst)))
+		default:
+			// Z_DATA_ERROR indicates that the compressed data is corrupted or incomplete.
+			return dst, 0, capi.ZErrorOp(capi.OpUncompress, ret)
+		}
+	}
+}
+
+// spare returns the spare capacity of dst up to size bytes.
+func spare(dst []byte, size int) []byte {
+	return dst[len(dst) : len(dst)+min(cap(dst)-len(dst), size)]
+}
+
+// growUncompressOutput makes sure that dst has spare capacity for more output.
+// It keeps the spare capacity of dst if any. Otherwise, it grows dst by size bytes and by at least uncompressMinSize.
+func growUncompressOutput(dst []byte, size int) []byte {
+	if cap(dst)-len(dst) > 1 {
+		return dst
+	}
+	return slices.Grow(dst, max(size, uncompressMinSize))
+}
+
+func isCompress2Options(opts common.CompressOptions) bool {
+	return opts.Header() == common.HeaderTypeZlib &&
+		opts.WindowBits() == 15 &&
+		opts.MemoryLevel() == 8 &&
+		opts.Strategy() == common.StrategyDefault &&
+		opts.InitialDictionary() == nil &&
+		opts.Tuning() == nil &&
+		opts.Observer() == nil &&
+		opts.Logger() == nil &&
+		opts.Context() == nil
+}
+
+func isUncompress2Options(opts common.DecompressOptions) bool {
+	return opts.Header() == common.HeaderTypeZlib &&
+		opts.WindowBits() == 15 &&
+		opts.InitialDictionary() == nil &&
+		opts.Recovery() == nil &&
+		opts.MemberHandler() == nil &&
+		opts.DictionaryResolver() == nil &&
+		opts.Observer() == nil &&
+		opts.Logger() == nil &&
+		opts.Context() == nil &&
+		opts.MaxOutputSize() == 0 &&
+		opts.MaxRatio() == 0 &&
+		opts.MaxWindowBits() == 0 &&
+		!opts.MultiMember()
+}
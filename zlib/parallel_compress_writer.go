@@ -0,0 +1,505 @@
+package zlib
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+// DefaultParallelBlockSize is the block size used by NewParallelCompressWriter when blockSize is not positive.
+const DefaultParallelBlockSize = 128 << 10
+
+// NewParallelCompressWriter writes compressed data to target while compressing blocks of blockSize bytes on
+// up to workers goroutines. If blockSize is not positive, DefaultParallelBlockSize is used.
+// If workers is not positive, runtime.GOMAXPROCS(0) is used.
+//
+// Each block is compressed as raw deflate data by a separate zlib stream primed with the window preceding the block.
+// The blocks are stitched into one stream using sync flushes. The zlib or gzip header and trailer are written around
+// the blocks and the checksum of the whole stream is computed by combining the checksums of the blocks.
+// The result is a single valid stream which can be decompressed by any zlib or gzip decompressor.
+// The compression ratio is slightly worse than NewCompressWriter because of the sync flushes between blocks.
+//
+// Flush waits until all the data written so far is compressed and written to target.
+// Close concludes the compression process. Write and Flush methods can not be called after Close.
+// Close must be called, also after an error, since it stops the goroutine writing to target and releases
+// the zlib state of the workers. Otherwise, the goroutine stays blocked forever.
+func NewParallelCompressWriter(target io.Writer, opts common.CompressOptions, blockSize int, workers int) (common.WriteFlushCloser, error) {
+	if blockSize <= 0 {
+		blockSize = DefaultParallelBlockSize
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Header() != common.HeaderTypeZlib && opts.Header() != common.HeaderTypeRaw && opts.Header() != common.HeaderTypeGzip {
+		return nil, fmt.Errorf("zlib: header type %d is not supported for compression", opts.Header())
+	}
+	if opts.Header() == common.HeaderTypeGzip && opts.InitialDictionary() != nil {
+		return nil, fmt.Errorf("zlib: initial dictionary is not supported with gzip header")
+	}
+
+	windowBits := opts.WindowBits()
+	if windowBits == 8 {
+		// zlib uses a window of 9 bits when 8 is requested for compression.
+		windowBits = 9
+	}
+
+	// Each block is compressed as a raw deflate stream.
+	// The dictionary is replaced by the window preceding each block.
+	blockOpts := opts.Clone().
+		WithWindowBits(windowBits).
+		WithHeader(common.HeaderTypeRaw).
+		WithInitialDictionary(nil).
+		WithGzipHeader(nil)
+
+	var header []byte
+	var check uint32
+	switch opts.Header() {
+	case common.HeaderTypeZlib:
+		header = zlibHeader(windowBits, opts.Level(), opts.Strategy(), opts.InitialDictionary())
+		check = 1
+	case common.HeaderTypeGzip:
+		var err error
+		header, err = gzipHeader(opts.GzipHeader(), opts.Level(), opts.Strategy())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the first compressor to validate the options.
+	zcompressor, err := compression.NewCompressor(blockOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &parallelCompressWriter{
+		target:       target,
+		opts:         opts,
+		blockOpts:    blockOpts,
+		blockSize:    blockSize,
+		windowSize:   1 << windowBits,
+		semaphore:    make(chan struct{}, workers),
+		blocks:       make(chan *parallelBlock, workers),
+		done:         make(chan struct{}),
+		workerStates: make(chan *parallelWorkerState, workers),
+		header:       header,
+		check:        check,
+	}
+	w.workerStates <- w.newWorkerState(zcompressor)
+	w.block = w.newBlockBuffer()
+
+	if opts.InitialDictionary() != nil {
+		w.history = lastBytes(opts.InitialDictionary(), w.windowSize)
+	}
+
+	go w.writeBlocks()
+
+	return w, nil
+}
+
+// parallelOutputBufferSize is the size of the buffer used by each worker to consume compressed data.
+const parallelOutputBufferSize = 64 << 10
+
+type parallelCompressWriter struct {
+	target     io.Writer
+	opts       common.CompressOptions
+	blockOpts  common.CompressOptions
+	blockSize  int
+	windowSize int
+
+	// block is the block being filled by Write.
+	block []byte
+	// history is the last windowSize bytes preceding block. It primes the compressor of block.
+	history []byte
+	closed  bool
+
+	// semaphore limits the number of blocks being compressed at the same time.
+	semaphore chan struct{}
+	// blocks are the blocks in their order in the stream. They are written by writeBlocks once compressed.
+	blocks chan *parallelBlock
+	// pending counts the blocks which have not been written to target yet.
+	pending sync.WaitGroup
+	// done is closed when writeBlocks returns.
+	done chan struct{}
+
+	// workerStates holds the states of the workers which are not compressing a block.
+	// The semaphore makes sure that there are never more states than workers.
+	workerStates chan *parallelWorkerState
+	// compressors are all the compressors created by the workers. They are closed by Close.
+	compressors      []compression.Compressor
+	compressorsMutex sync.Mutex
+
+	// The following fields are only accessed by writeBlocks.
+	header        []byte
+	headerWritten bool
+	check         uint32
+	totalIn       int64
+
+	errMutex sync.Mutex
+	err      error
+}
+
+type parallelBlock struct {
+	input      []byte
+	dictionary []byte
+	flush      compression.Flush
+
+	compressed []byte
+	check      uint32
+	err        error
+	done       chan struct{}
+}
+
+type parallelWorkerState struct {
+	compressor   compression.Compressor
+	outputBuffer []byte
+}
+
+// Write writes decompressed data which will be compressed and written to target.
+// It returns as soon as the data is handed to the workers. Errors may be reported by later calls.
+func (w *parallelCompressWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("zlib: write to a closed parallel compress writer")
+	}
+
+	written := 0
+	for written < len(p) {
+		if err := w.getErr(); err != nil {
+			return written, err
+		}
+
+		n := copy(w.block[len(w.block):w.blockSize], p[written:])
+		w.block = w.block[:len(w.block)+n]
+		written += n
+
+		if len(w.block) == w.blockSize {
+			w.dispatch(compression.SyncFlush)
+		}
+	}
+	return written, w.getErr()
+}
+
+// Flush waits until the data written so far is compressed and written to target.
+func (w *parallelCompressWriter) Flush() error {
+	if w.closed {
+		return w.getErr()
+	}
+
+	if len(w.block) > 0 {
+		w.dispatch(compression.SyncFlush)
+	}
+	w.pending.Wait()
+	return w.getErr()
+}
+
+// Close concludes the compression process and writes the trailer to target.
+func (w *parallelCompressWriter) Close() error {
+	if w.closed {
+		return w.getErr()
+	}
+	w.closed = true
+
+	w.dispatch(compression.Finish)
+	close(w.blocks)
+	<-w.done
+
+	closeErr := w.closeCompressors()
+	if err := w.getErr(); err != nil {
+		return err
+	}
+	if err := w.writeTrailer(); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// closeCompressors closes the compressors of the workers once all blocks are compressed.
+func (w *parallelCompressWriter) closeCompressors() error {
+	w.compressorsMutex.Lock()
+	defer w.compressorsMutex.Unlock()
+
+	var firstErr error
+	for _, zcompressor := range w.compressors {
+		if err := zcompressor.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	w.compressors = nil
+	return firstErr
+}
+
+// dispatch hands the current block to a worker and starts a new block.
+func (w *parallelCompressWriter) dispatch(flush compression.Flush) {
+	block := &parallelBlock{
+		input:      w.block,
+		dictionary: w.history,
+		flush:      flush,
+		done:       make(chan struct{}),
+	}
+
+	w.history = appendHistory(w.history, w.block, w.windowSize)
+	w.block = w.newBlockBuffer()
+
+	w.pending.Add(1)
+	w.semaphore <- struct{}{}
+	go func() {
+		defer func() { <-w.semaphore }()
+		w.compressBlock(block)
+	}()
+	w.blocks <- block
+}
+
+func (w *parallelCompressWriter) newBlockBuffer() []byte {
+	// The input buffer must have capacity larger than its size by at least one.
+	return make([]byte, 0, w.blockSize+1)
+}
+
+// newWorkerState creates the state of a worker and keeps its compressor to be closed by Close.
+func (w *parallelCompressWriter) newWorkerState(zcompressor compression.Compressor) *parallelWorkerState {
+	w.compressorsMutex.Lock()
+	defer w.compressorsMutex.Unlock()
+	w.compressors = append(w.compressors, zcompressor)
+	return &parallelWorkerState{compressor: zcompressor, outputBuffer: make([]byte, parallelOutputBufferSize)}
+}
+
+func (w *parallelCompressWriter) compressBlock(block *parallelBlock) {
+	defer close(block.done)
+
+	switch w.opts.Header() {
+	case common.HeaderTypeZlib:
+		block.check = capi.Adler32(1, block.input)
+	case common.HeaderTypeGzip:
+		block.check = capi.Crc32(0, block.input)
+	}
+
+	var state *parallelWorkerState
+	select {
+	case state = <-w.workerStates:
+	default:
+		zcompressor, err := compression.NewCompressor(w.blockOpts)
+		if err != nil {
+			block.err = err
+			return
+		}
+		state = w.newWorkerState(zcompressor)
+	}
+	defer func() { w.workerStates <- state }()
+
+	// The options are shared between workers. Copy them to set the dictionary of this block.
+	opts := w.blockOpts.Clone().WithInitialDictionary(block.dictionary)
+	if err := state.compressor.Reset(opts); err != nil {
+		block.err = err
+		return
+	}
+
+	n, err := state.compressor.Feed(block.input, block.flush, state.outputBuffer)
+	block.compressed = append(block.compressed, state.outputBuffer[:n]...)
+	for err == nil && state.compressor.CanCallConsume() {
+		n, err = state.compressor.Consume(state.outputBuffer)
+		block.compressed = append(block.compressed, state.outputBuffer[:n]...)
+	}
+	if err != nil && err != io.EOF {
+		block.err = err
+	}
+}
+
+// writeBlocks writes the compressed blocks to target in their order in the stream.
+func (w *parallelCompressWriter) writeBlocks() {
+	defer close(w.done)
+
+	for block := range w.blocks {
+		<-block.done
+		w.writeBlock(block)
+		w.pending.Done()
+	}
+}
+
+func (w *parallelCompressWriter) writeBlock(block *parallelBlock) {
+	if w.getErr() != nil {
+		return
+	}
+	if block.err != nil {
+		w.setErr(block.err)
+		return
+	}
+
+	if !w.headerWritten {
+		w.headerWritten = true
+		if err := writeFull(w.target, w.header); err != nil {
+			w.setErr(err)
+			return
+		}
+	}
+
+	if err := writeFull(w.target, block.compressed); err != nil {
+		w.setErr(err)
+		return
+	}
+
+	switch w.opts.Header() {
+	case common.HeaderTypeZlib:
+		w.check = capi.Adler32Combine(w.check, block.check, int64(len(block.input)))
+	case common.HeaderTypeGzip:
+		w.check = capi.Crc32Combine(w.check, block.check, int64(len(block.input)))
+	}
+	w.totalIn += int64(len(block.input))
+}
+
+func (w *parallelCompressWriter) writeTrailer() error {
+	var trailer []byte
+	switch w.opts.Header() {
+	case common.HeaderTypeZlib:
+		trailer = binary.BigEndian.AppendUint32(trailer, w.check)
+	case common.HeaderTypeGzip:
+		trailer = binary.LittleEndian.AppendUint32(trailer, w.check)
+		trailer = binary.LittleEndian.AppendUint32(trailer, uint32(w.totalIn))
+	}
+	err := writeFull(w.target, trailer)
+	if err != nil {
+		w.setErr(err)
+	}
+	return err
+}
+
+func (w *parallelCompressWriter) getErr() error {
+	w.errMutex.Lock()
+	defer w.errMutex.Unlock()
+	return w.err
+}
+
+func (w *parallelCompressWriter) setErr(err error) {
+	w.errMutex.Lock()
+	defer w.errMutex.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func writeFull(w io.Writer, p []byte) error {
+	n, err := w.Write(p)
+	if err != nil {
+		return err
+	}
+	if n != len(p) {
+		return fmt.Errorf("short write %w", io.ErrShortWrite)
+	}
+	return nil
+}
+
+// appendHistory returns the last size bytes of history followed by data.
+// The returned slice is never modified afterwards because it is used as a dictionary by a worker.
+func appendHistory(history []byte, data []byte, size int) []byte {
+	if len(data) >= size {
+		return data[len(data)-size:]
+	}
+	keep := size - len(data)
+	if keep > len(history) {
+		keep = len(history)
+	}
+	newHistory := make([]byte, 0, keep+len(data))
+	newHistory = append(newHistory, history[len(history)-keep:]...)
+	return append(newHistory, data...)
+}
+
+func lastBytes(data []byte, size int) []byte {
+	if len(data) > size {
+		return data[len(data)-size:]
+	}
+	return data
+}
+
+// zlibHeader builds the zlib header the same way deflate does.
+// See RFC 1950 for the format.
+func zlibHeader(windowBits int, level int, strategy common.StrategyType, dictionary []byte) []byte {
+	if level == int(capi.Z_DEFAULT_COMPRESSION) {
+		level = 6
+	}
+
+	var levelFlags byte
+	switch {
+	case strategy >= common.StrategyHuffmanOnly || level < 2:
+		levelFlags = 0
+	case level < 6:
+		levelFlags = 1
+	case level == 6:
+		levelFlags = 2
+	default:
+		levelFlags = 3
+	}
+
+	cmf := byte(windowBits-8)<<4 | byte(capi.Z_DEFLATED)
+	flg := levelFlags << 6
+	if dictionary != nil {
+		flg |= 0x20
+	}
+	flg += byte(31 - (uint16(cmf)<<8|uint16(flg))%31)
+
+	header := []byte{cmf, flg}
+	if dictionary != nil {
+		header = binary.BigEndian.AppendUint32(header, capi.Adler32(1, dictionary))
+	}
+	return header
+}
+
+// gzipHeader builds the gzip header the same way deflate does.
+// See RFC 1952 for the format.
+func gzipHeader(h *common.GzipHeader, level int, strategy common.StrategyType) ([]byte, error) {
+	if h == nil {
+		// Without a gzip header, deflate writes its OS_CODE which is the code of Unix.
+		h = &common.GzipHeader{OS: common.GzipOSUnix}
+	}
+
+	var flags byte
+	if h.Text {
+		flags |= 0x01
+	}
+	if h.HCRC {
+		flags |= 0x02
+	}
+	if h.Extra != nil {
+		flags |= 0x04
+	}
+	if h.Name != "" {
+		flags |= 0x08
+	}
+	if h.Comment != "" {
+		flags |= 0x10
+	}
+
+	mtime, err := h.EncodedModTime()
+	if err != nil {
+		return nil, err
+	}
+
+	var xfl byte
+	if level == 9 {
+		xfl = 2
+	} else if strategy >= common.StrategyHuffmanOnly || (level >= 0 && level < 2) {
+		xfl = 4
+	}
+
+	header := []byte{0x1f, 0x8b, byte(capi.Z_DEFLATED), flags}
+	header = binary.LittleEndian.AppendUint32(header, mtime)
+	header = append(header, xfl, h.EncodedOS())
+	if h.Extra != nil {
+		header = binary.LittleEndian.AppendUint16(header, uint16(len(h.Extra)))
+		header = append(header, h.Extra...)
+	}
+	if h.Name != "" {
+		header = append(header, h.Name...)
+		header = append(header, 0)
+	}
+	if h.Comment != "" {
+		header = append(header, h.Comment...)
+		header = append(header, 0)
+	}
+	if h.HCRC {
+		header = binary.LittleEndian.AppendUint16(header, uint16(capi.Crc32(0, header)))
+	}
+	return header, nil
+}
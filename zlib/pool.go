@@ -0,0 +1,276 @@
+package zlib
+
+import (
+	"hash/maphash"
+	"io"
+	"sync"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// Pool hands out ready-to-use compress/decompress writers and readers.
+// Closing a value obtained from a Pool returns it to the Pool instead of releasing its resources.
+// The zlib state and the buffers are then reused by the next Get with a matching set of options.
+//
+// Values are kept in separate pools per option fingerprint: level, window bits, memory level,
+// strategy, header, dictionary and buffer size. This makes it likely that a reused value only
+// needs deflateReset or inflateReset2 instead of a full initialization.
+//
+// A value must not be used after Close. Values that are not closed are not returned to the Pool.
+// The resources of values dropped by the Pool are released when they are garbage collected.
+// A Pool is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	seed  maphash.Seed
+	pools sync.Map // poolKey -> *sync.Pool
+}
+
+// NewPool creates a new empty Pool.
+func NewPool() *Pool {
+	return &Pool{
+		seed: maphash.MakeSeed(),
+	}
+}
+
+type poolKind int
+
+const (
+	poolKindCompressWriter poolKind = iota
+	poolKindCompressReader
+	poolKindDecompressWriter
+	poolKindDecompressReader
+)
+
+// poolKey is the fingerprint of the options used to split the pools.
+type poolKey struct {
+	kind          poolKind
+	level         int
+	windowBits    int
+	memoryLevel   int
+	strategy      common.StrategyType
+	header        common.HeaderType
+	hasDictionary bool
+	dictionary    uint64
+	bufferSize    int
+}
+
+func (p *Pool) compressKey(kind poolKind, opts common.CompressOptions) poolKey {
+	key := poolKey{
+		kind:        kind,
+		level:       opts.Level(),
+		windowBits:  opts.WindowBits(),
+		memoryLevel: opts.MemoryLevel(),
+		strategy:    opts.Strategy(),
+		header:      opts.Header(),
+		bufferSize:  opts.BufferSize(),
+	}
+	p.setDictionary(&key, opts.InitialDictionary())
+	return key
+}
+
+func (p *Pool) decompressKey(kind poolKind, opts common.DecompressOptions) poolKey {
+	key := poolKey{
+		kind:       kind,
+		windowBits: opts.WindowBits(),
+		header:     opts.Header(),
+		bufferSize: opts.BufferSize(),
+	}
+	p.setDictionary(&key, opts.InitialDictionary())
+	return key
+}
+
+// setDictionary sets the dictionary fingerprint. A hash collision is harmless because
+// the options are always fully applied by Reset. It only costs a full initialization.
+func (p *Pool) setDictionary(key *poolKey, dictionary []byte) {
+	if dictionary != nil {
+		key.hasDictionary = true
+		key.dictionary = maphash.Bytes(p.seed, dictionary)
+	}
+}
+
+func (p *Pool) pool(key poolKey) *sync.Pool {
+	pool, ok := p.pools.Load(key)
+	if !ok {
+		pool, _ = p.pools.LoadOrStore(key, &sync.Pool{})
+	}
+	return pool.(*sync.Pool)
+}
+
+// GetCompressWriter is similar to NewCompressWriter but reuses a pooled writer when available.
+// Close concludes the compression process and returns the writer to the Pool.
+func (p *Pool) GetCompressWriter(target io.Writer, opts common.CompressOptions) (common.WriteFlushCloser, error) {
+	pool := p.pool(p.compressKey(poolKindCompressWriter, opts))
+	if w, ok := pool.Get().(*pooledCompressWriter); ok {
+		err := w.Reset(target, opts)
+		if err != nil {
+			return nil, err
+		}
+		w.closed = false
+		return w, nil
+	}
+
+	w, err := NewCompressWriter(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledCompressWriter{
+		compressWriter: w.(*compressWriter),
+		pool:           pool,
+		bufferSize:     opts.BufferSize(),
+	}, nil
+}
+
+// GetCompressReader is similar to NewCompressReader but reuses a pooled reader when available.
+// Close returns the reader to the Pool.
+func (p *Pool) GetCompressReader(target io.Reader, opts common.CompressOptions) (io.ReadCloser, error) {
+	pool := p.pool(p.compressKey(poolKindCompressReader, opts))
+	if r, ok := pool.Get().(*pooledCompressReader); ok {
+		err := r.Reset(target, opts)
+		if err != nil {
+			return nil, err
+		}
+		r.closed = false
+		return r, nil
+	}
+
+	r, err := NewCompressReader(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledCompressReader{
+		compressReader: r.(*compressReader),
+		pool:           pool,
+		bufferSize:     opts.BufferSize(),
+	}, nil
+}
+
+// GetDecompressWriter is similar to NewDecompressWriter but reuses a pooled writer when available.
+// Close concludes the decompression process and returns the writer to the Pool.
+func (p *Pool) GetDecompressWriter(target io.Writer, opts common.DecompressOptions) (common.WriteFlushCloser, error) {
+	pool := p.pool(p.decompressKey(poolKindDecompressWriter, opts))
+	if w, ok := pool.Get().(*pooledDecompressWriter); ok {
+		err := w.Reset(target, opts)
+		if err != nil {
+			return nil, err
+		}
+		w.closed = false
+		return w, nil
+	}
+
+	w, err := NewDecompressWriter(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledDecompressWriter{
+		decompressWriter: w.(*decompressWriter),
+		pool:             pool,
+		bufferSize:       opts.BufferSize(),
+	}, nil
+}
+
+// GetDecompressReader is similar to NewDecompressReader but reuses a pooled reader when available.
+// Close returns the reader to the Pool.
+func (p *Pool) GetDecompressReader(target io.Reader, opts common.DecompressOptions) (io.ReadCloser, error) {
+	pool := p.pool(p.decompressKey(poolKindDecompressReader, opts))
+	if r, ok := pool.Get().(*pooledDecompressReader); ok {
+		err := r.Reset(target, opts)
+		if err != nil {
+			return nil, err
+		}
+		r.closed = false
+		return r, nil
+	}
+
+	r, err := NewDecompressReader(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledDecompressReader{
+		decompressReader: r.(*decompressReader),
+		pool:             pool,
+		bufferSize:       opts.BufferSize(),
+	}, nil
+}
+
+// The pooled types keep the zlib state on Close so that it can be reused.
+// Their target and the context, observer, logger and handlers of their options are dropped
+// before returning them to the pool to avoid holding on to them.
+
+type pooledCompressWriter struct {
+	*compressWriter
+	pool       *sync.Pool
+	bufferSize int
+	closed     bool
+}
+
+// Close concludes the compression process and returns the writer to the Pool.
+func (w *pooledCompressWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.impl.Close()
+	w.impl.Reset(nil, w.bufferSize)
+	w.compressor.ClearOptions()
+	w.pool.Put(w)
+	return err
+}
+
+type pooledCompressReader struct {
+	*compressReader
+	pool       *sync.Pool
+	bufferSize int
+	closed     bool
+}
+
+// Close returns the reader to the Pool.
+func (r *pooledCompressReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.impl.Close()
+	r.impl.Reset(nil, r.bufferSize)
+	r.compressor.ClearOptions()
+	r.pool.Put(r)
+	return err
+}
+
+type pooledDecompressWriter struct {
+	*decompressWriter
+	pool       *sync.Pool
+	bufferSize int
+	closed     bool
+}
+
+// Close concludes the decompression process and returns the writer to the Pool.
+func (w *pooledDecompressWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.impl.Close()
+	w.impl.Reset(nil, w.bufferSize)
+	w.decompressor.ClearOptions()
+	w.pool.Put(w)
+	return err
+}
+
+type pooledDecompressReader struct {
+	*decompressReader
+	pool       *sync.Pool
+	bufferSize int
+	closed     bool
+}
+
+// Close returns the reader to the Pool.
+func (r *pooledDecompressReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.impl.Close()
+	r.impl.Reset(nil, r.bufferSize)
+	r.decompressor.ClearOptions()
+	r.pool.Put(r)
+	return err
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestAutoHeaderDetection(t *testing.T) {
+	data := RandBytes(10 + 1<<15)
+	headers := []common.HeaderType{
+		common.HeaderTypeZlib,
+		common.HeaderTypeGzip,
+	}
+	windowBits := []int{0, 9, 15}
+
+	for _, header := range headers {
+		for _, w := range windowBits {
+			t.Run(fmt.Sprintf("h:%d w:%d", header, w), func(t *testing.T) {
+				compressOpts := common.DefaultCompressOptions().WithHeader(header).WithWindowBits(9)
+				compressed, err := synchronousCompressReader(t, data, compressOpts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				decompressOpts := common.DefaultDecompressOptions().WithHeader(common.HeaderTypeAuto).WithWindowBits(w)
+				decompressReader, err := zlib.NewDecompressReader(bytes.NewReader(compressed), decompressOpts)
+				if err != nil {
+					t.Fatalf("Error creating decompress reader: %v", err)
+				}
+
+				if _, ok := decompressReader.(common.HeaderDetector).DetectedHeader(); ok {
+					t.Fatalf("The header should not be detected before reading")
+				}
+
+				decompressed, err := io.ReadAll(decompressReader)
+				if err != nil {
+					t.Fatalf("Error reading from decompress reader: %v", err)
+				}
+				if !bytes.Equal(decompressed, data) {
+					t.Fatalf("decompressed data is not equal to the original data")
+				}
+
+				detected, ok := decompressReader.(common.HeaderDetector).DetectedHeader()
+				if !ok || detected != header {
+					t.Fatalf("Expected detected header %d, got %d (ok: %v)", header, detected, ok)
+				}
+			})
+		}
+	}
+}
+
+func TestWindowBitsFromZlibHeader(t *testing.T) {
+	data := RandBytes(10 + 1<<15)
+	for _, w := range []int{9, 12, 15} {
+		t.Run(fmt.Sprintf("w:%d", w), func(t *testing.T) {
+			compressOpts := common.DefaultCompressOptions().WithWindowBits(w)
+			compressed, err := synchronousCompressReader(t, data, compressOpts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decompressed, err := synchronousDecompressWriter(t, compressed, common.DefaultDecompressOptions().WithWindowBits(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Fatalf("decompressed data is not equal to the original data")
+			}
+		})
+	}
+}
+
+func TestAutoHeaderRejectedForCompression(t *testing.T) {
+	opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeAuto)
+	if _, err := zlib.NewCompressWriter(io.Discard, opts); err == nil || !strings.Contains(err.Error(), "only supported for decompression") {
+		t.Fatalf("expected HeaderTypeAuto to be rejected, got %v", err)
+	}
+
+	// Reset rejects it too.
+	w, err := zlib.NewCompressWriter(io.Discard, common.DefaultCompressOptions())
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	defer w.Close()
+	if err := w.(common.CompressWriterResetter).Reset(io.Discard, opts); err == nil || !strings.Contains(err.Error(), "only supported for decompression") {
+		t.Fatalf("expected HeaderTypeAuto to be rejected by Reset, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"hash/adler32"
+	"hash/crc32"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/checksum"
+)
+
+func TestChecksums(t *testing.T) {
+	for _, size := range []int{0, 1, 100, 100000} {
+		data := RandBytes(size)
+
+		adler := checksum.NewAdler32()
+		crc := checksum.NewCRC32()
+		for start := 0; start < len(data); start += 7 {
+			end := min(start+7, len(data))
+			adler.Write(data[start:end])
+			crc.Write(data[start:end])
+		}
+		if adler.Sum32() != adler32.Checksum(data) || checksum.ChecksumAdler32(data) != adler32.Checksum(data) {
+			t.Fatalf("Adler-32 of %d bytes does not match hash/adler32", size)
+		}
+		if crc.Sum32() != crc32.ChecksumIEEE(data) || checksum.ChecksumCRC32(data) != crc32.ChecksumIEEE(data) {
+			t.Fatalf("CRC-32 of %d bytes does not match hash/crc32", size)
+		}
+		expectedAdler := adler32.New()
+		expectedAdler.Write(data)
+		expectedCRC := crc32.NewIEEE()
+		expectedCRC.Write(data)
+		if string(adler.Sum([]byte("x"))) != string(expectedAdler.Sum([]byte("x"))) {
+			t.Fatalf("Adler-32 Sum of %d bytes does not match hash/adler32", size)
+		}
+		if string(crc.Sum([]byte("x"))) != string(expectedCRC.Sum([]byte("x"))) {
+			t.Fatalf("CRC-32 Sum of %d bytes does not match hash/crc32", size)
+		}
+
+		adler.Reset()
+		crc.Reset()
+		if adler.Sum32() != adler32.Checksum(nil) || crc.Sum32() != crc32.ChecksumIEEE(nil) {
+			t.Fatalf("Reset did not restore the checksum of empty data")
+		}
+	}
+}
+
+func TestChecksumZeroValue(t *testing.T) {
+	data := RandBytes(1000)
+	var adler checksum.Adler32
+	var crc checksum.CRC32
+	if adler.Sum32() != adler32.Checksum(nil) || crc.Sum32() != crc32.ChecksumIEEE(nil) {
+		t.Fatalf("the zero value is not the checksum of empty data")
+	}
+	adler.Write(data)
+	crc.Write(data)
+	if adler.Sum32() != adler32.Checksum(data) || crc.Sum32() != crc32.ChecksumIEEE(data) {
+		t.Fatalf("the checksums of the zero value do not match hash/adler32 and hash/crc32")
+	}
+}
+
+func TestChecksumCombine(t *testing.T) {
+	const chunkSize = 1000
+	data := RandBytes(10*chunkSize + 123)
+
+	adler := checksum.NewAdler32()
+	crc := checksum.NewCRC32()
+	adlerSum := checksum.ChecksumAdler32(nil)
+	crcSum := checksum.ChecksumCRC32(nil)
+	combinedCRC := checksum.ChecksumCRC32(nil)
+	combiner := checksum.NewCRC32Combiner(chunkSize)
+	for start := 0; start < len(data); start += chunkSize {
+		chunk := data[start:min(start+chunkSize, len(data))]
+		adler.Combine(checksum.ChecksumAdler32(chunk), int64(len(chunk)))
+		crc.Combine(checksum.ChecksumCRC32(chunk), int64(len(chunk)))
+		adlerSum = checksum.CombineAdler32(adlerSum, checksum.ChecksumAdler32(chunk), int64(len(chunk)))
+		crcSum = checksum.CombineCRC32(crcSum, checksum.ChecksumCRC32(chunk), int64(len(chunk)))
+		if len(chunk) == chunkSize {
+			combinedCRC = combiner.Combine(combinedCRC, checksum.ChecksumCRC32(chunk))
+		} else {
+			combinedCRC = checksum.CombineCRC32(combinedCRC, checksum.ChecksumCRC32(chunk), int64(len(chunk)))
+		}
+	}
+
+	if adler.Sum32() != adler32.Checksum(data) || adlerSum != adler32.Checksum(data) {
+		t.Fatalf("combined Adler-32 does not match the checksum of the whole data")
+	}
+	if crc.Sum32() != crc32.ChecksumIEEE(data) || crcSum != crc32.ChecksumIEEE(data) || combinedCRC != crc32.ChecksumIEEE(data) {
+		t.Fatalf("combined CRC-32 does not match the checksum of the whole data")
+	}
+}
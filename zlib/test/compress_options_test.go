@@ -1,6 +1,11 @@
 package test
 
 import (
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+
 	"github.com/MeenaAlfons/go-zlib/zlib/common"
 )
 
@@ -53,3 +58,30 @@ func getCompressOptionsCombinations() []common.CompressOptions {
 
 	return optsList
 }
+
+func TestCompressOptionsClone(t *testing.T) {
+	opts := common.DefaultCompressOptions().
+		WithLevel(9).
+		WithWindowBits(12).
+		WithHeader(common.HeaderTypeGzip).
+		WithMemoryLevel(9).
+		WithStrategy(common.StrategyRLE).
+		WithBufferSize(4096).
+		WithInitialDictionary([]byte("dictionary")).
+		WithGzipHeader(&common.GzipHeader{Name: "clone"}).
+		WithTuning(&common.Tuning{GoodLength: 4, MaxLazy: 4, NiceLength: 8, MaxChain: 2}).
+		WithObserver(common.NopObserver{}).
+		WithLogger(slog.Default()).
+		WithContext(context.Background())
+
+	clone := opts.Clone()
+	if !reflect.DeepEqual(clone, opts) {
+		t.Fatalf("expected the clone to be equal to the options")
+	}
+
+	clone.WithLevel(1).WithInitialDictionary(nil)
+	clone.Tuning().MaxChain = 1
+	if opts.Level() != 9 || opts.InitialDictionary() == nil || opts.Tuning().MaxChain != 2 {
+		t.Fatalf("changing the clone changed the options")
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestContextOneShot(t *testing.T) {
+	data := compressibleBytes(1 << 20)
+	ctx := context.Background()
+	compressed, err := zlib.Compress(nil, data, common.DefaultCompressOptions().WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	output, consumed, err := zlib.Uncompress(nil, compressed, common.DefaultDecompressOptions().WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+	if !bytes.Equal(output, data) || consumed != len(compressed) {
+		t.Fatalf("decompressed data does not match")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = zlib.Compress(nil, data, common.DefaultCompressOptions().WithContext(canceled))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	_, _, err = zlib.Uncompress(nil, compressed, common.DefaultDecompressOptions().WithContext(expired))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestContextCompressWriter(t *testing.T) {
+	data := compressibleBytes(100000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, common.DefaultCompressOptions().WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Error writing: %v", err)
+	}
+	cancel()
+	if _, err := w.Write(data); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if err := w.Flush(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from Flush, got %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from Close, got %v", err)
+	}
+}
+
+func TestContextDecompressReader(t *testing.T) {
+	data := compressibleBytes(100000)
+	compressed, err := zlib.Compress(nil, data, common.DefaultCompressOptions())
+	if err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions().WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	defer r.Close()
+	p := make([]byte, 1000)
+	if _, err := io.ReadFull(r, p); err != nil {
+		t.Fatalf("Error reading: %v", err)
+	}
+	cancel()
+	if _, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
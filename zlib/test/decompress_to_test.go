@@ -0,0 +1,114 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+func TestDecompressTo(t *testing.T) {
+	for _, data := range [][]byte{{}, []byte("Hello World!"), compressibleBytes(1 << 20), RandBytes(1 << 18)} {
+		compressed := compressWith(t, data, common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw))
+
+		var decompressed bytes.Buffer
+		n, err := zlib.DecompressTo(&decompressed, bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("Error decompressing: %v", err)
+		}
+		if n != int64(len(data)) || !bytes.Equal(decompressed.Bytes(), data) {
+			t.Fatalf("decompressed data is not equal to the original data")
+		}
+	}
+}
+
+func TestDecompressToErrors(t *testing.T) {
+	data := compressibleBytes(1 << 18)
+	compressed := compressWith(t, data, common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw))
+
+	_, err := zlib.DecompressTo(io.Discard, bytes.NewReader(compressed[:len(compressed)/2]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF for truncated data, got %v", err)
+	}
+
+	writeErr := errors.New("write failed")
+	_, err = zlib.DecompressTo(failingWriter{writeErr}, bytes.NewReader(compressed))
+	if err != writeErr {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+
+	corrupted := append([]byte{0xff, 0xff}, compressed...)
+	if _, err := zlib.DecompressTo(io.Discard, bytes.NewReader(corrupted)); err == nil {
+		t.Fatalf("expected an error for corrupted data")
+	}
+}
+
+func TestDecompressToNoProgress(t *testing.T) {
+	if _, err := zlib.DecompressTo(io.Discard, emptyReader{}); err != io.ErrNoProgress {
+		t.Fatalf("expected io.ErrNoProgress for a reader returning no data, got %v", err)
+	}
+}
+
+// emptyReader always returns no data and no error.
+type emptyReader struct{}
+
+func (emptyReader) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+func benchmarkCompressedRaw(b *testing.B) ([]byte, int) {
+	data := compressibleBytes(8 << 20)
+	compressed, err := zlib.Compress(nil, data, common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw))
+	if err != nil {
+		b.Fatalf("Error compressing: %v", err)
+	}
+	// The input needs spare capacity when it is fed to zlib.
+	return append(make([]byte, 0, len(compressed)+1), compressed...), len(data)
+}
+
+func BenchmarkDecompressTo(b *testing.B) {
+	compressed, size := benchmarkCompressedRaw(b)
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := zlib.DecompressTo(io.Discard, bytes.NewReader(compressed)); err != nil {
+			b.Fatalf("Error decompressing: %v", err)
+		}
+	}
+}
+
+// BenchmarkDecompressFeedConsume is the baseline of BenchmarkDecompressTo using Feed and Consume
+// with an output buffer of the default buffer size as used by NewDecompressReader.
+func BenchmarkDecompressFeedConsume(b *testing.B) {
+	compressed, size := benchmarkCompressedRaw(b)
+	opts := common.DefaultDecompressOptions().WithHeader(common.HeaderTypeRaw)
+	output := make([]byte, opts.BufferSize())
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decompressor, err := compression.NewDecompressor(opts)
+		if err != nil {
+			b.Fatalf("Error creating decompressor: %v", err)
+		}
+		_, err = decompressor.Feed(compressed, compression.Finish, output)
+		for err == nil && decompressor.CanCallConsume() {
+			_, err = decompressor.Consume(output)
+		}
+		if err != io.EOF {
+			b.Fatalf("Error decompressing: %v", err)
+		}
+		decompressor.Close()
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
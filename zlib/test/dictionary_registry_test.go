@@ -0,0 +1,81 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func schemaDictionaries() [][]byte {
+	return [][]byte{
+		[]byte(`{"version":1,"name":"","email":"","created":""}`),
+		[]byte(`{"version":2,"name":"","email":"","created":"","tags":[]}`),
+		[]byte(`{"version":3,"id":0,"name":"","emails":[],"created":"","tags":[]}`),
+	}
+}
+
+func TestDictionaryRegistry(t *testing.T) {
+	dictionaries := schemaDictionaries()
+	registry := common.NewDictionaryRegistry(dictionaries...)
+
+	var streams, inputs [][]byte
+	for i, dictionary := range dictionaries {
+		input := []byte(fmt.Sprintf(`{"version":%d,"name":"someone","email":"someone@example.com"}`, i+1))
+		inputs = append(inputs, input)
+		streams = append(streams, compressWith(t, input, common.DefaultCompressOptions().WithInitialDictionary(dictionary)))
+	}
+
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeAuto} {
+		for i, stream := range streams {
+			// The initial dictionary is only used when it matches. Otherwise, the registry is used.
+			opts := common.DefaultDecompressOptions().WithHeader(header).WithInitialDictionary(dictionaries[0]).WithDictionaryResolver(registry.Resolve)
+			output, _, err := zlib.Uncompress(nil, stream, opts)
+			if err != nil {
+				t.Fatalf("Error decompressing stream %d with header %v: %v", i, header, err)
+			}
+			if string(output) != string(inputs[i]) {
+				t.Fatalf("stream %d decompressed to %q instead of %q", i, output, inputs[i])
+			}
+		}
+	}
+
+	// Each member of a multi-member stream can use a different dictionary.
+	var members []byte
+	for _, stream := range streams {
+		members = append(members, stream...)
+	}
+	output, _, err := zlib.Uncompress(nil, members, common.DefaultDecompressOptions().WithMultiMember(true).WithDictionaryResolver(registry.Resolve))
+	if err != nil {
+		t.Fatalf("Error decompressing the members: %v", err)
+	}
+	if string(output) != strings.Join([]string{string(inputs[0]), string(inputs[1]), string(inputs[2])}, "") {
+		t.Fatalf("the members decompressed to %q", output)
+	}
+
+	registry.Remove(common.DictionaryID(dictionaries[1]))
+	_, _, err = zlib.Uncompress(nil, streams[1], common.DefaultDecompressOptions().WithDictionaryResolver(registry.Resolve))
+	if !errors.Is(err, common.ErrUnknownDictionary) || !errors.Is(err, zlib.ErrNeedDict) {
+		t.Fatalf("expected an unknown dictionary error, got %v", err)
+	}
+}
+
+func TestDictionaryMismatch(t *testing.T) {
+	dictionaries := schemaDictionaries()
+	stream := compressWith(t, []byte("some data"), common.DefaultCompressOptions().WithInitialDictionary(dictionaries[0]))
+	id := fmt.Sprintf("%08x", common.DictionaryID(dictionaries[0]))
+
+	_, _, err := zlib.Uncompress(nil, stream, common.DefaultDecompressOptions().WithInitialDictionary(dictionaries[1]))
+	if !errors.Is(err, zlib.ErrNeedDict) || !strings.Contains(err.Error(), id) {
+		t.Fatalf("expected a dictionary mismatch error mentioning %s, got %v", id, err)
+	}
+
+	wrongResolver := func(uint32) ([]byte, error) { return dictionaries[2], nil }
+	_, _, err = zlib.Uncompress(nil, stream, common.DefaultDecompressOptions().WithDictionaryResolver(wrongResolver))
+	if !errors.Is(err, zlib.ErrNeedDict) || !strings.Contains(err.Error(), id) {
+		t.Fatalf("expected a dictionary mismatch error mentioning %s, got %v", id, err)
+	}
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func decompressAll(compressed []byte, opts common.DecompressOptions) error {
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), opts)
+	if err != nil {
+		return err
+	}
+	_, err = io.ReadAll(r)
+	return err
+}
+
+func TestErrorIncorrectHeader(t *testing.T) {
+	err := decompressAll([]byte("this is not compressed data"), common.DefaultDecompressOptions())
+	if !errors.Is(err, zlib.ErrData) {
+		t.Fatalf("expected ErrData, got %v", err)
+	}
+	if errors.Is(err, zlib.ErrNeedDict) || errors.Is(err, zlib.ErrMem) {
+		t.Fatalf("expected only ErrData to match, got %v", err)
+	}
+	var zerr *zlib.ZlibError
+	if !errors.As(err, &zerr) {
+		t.Fatalf("expected a *ZlibError, got %T", err)
+	}
+	if zerr.Code != capi.Z_DATA_ERROR || zerr.Op != capi.OpInflate || zerr.Msg != "incorrect header check" {
+		t.Fatalf("unexpected error fields: %+v", zerr)
+	}
+}
+
+func TestErrorNeedDict(t *testing.T) {
+	dictionary := []byte("a dictionary that is needed to decompress")
+	compressed := compressWith(t, []byte("some data"), common.DefaultCompressOptions().WithInitialDictionary(dictionary))
+
+	err := decompressAll(compressed, common.DefaultDecompressOptions())
+	if !errors.Is(err, zlib.ErrNeedDict) {
+		t.Fatalf("expected ErrNeedDict, got %v", err)
+	}
+	if errors.Is(err, zlib.ErrData) {
+		t.Fatalf("expected ErrData not to match, got %v", err)
+	}
+
+	if err := decompressAll(compressed, common.DefaultDecompressOptions().WithInitialDictionary(dictionary)); err != nil {
+		t.Fatalf("Error decompressing with the dictionary: %v", err)
+	}
+}
+
+func TestErrorUncompress(t *testing.T) {
+	_, _, err := zlib.Uncompress(nil, []byte("this is not compressed data"), common.DefaultDecompressOptions())
+	var zerr *zlib.ZlibError
+	if !errors.As(err, &zerr) || !errors.Is(err, zlib.ErrData) || zerr.Op != capi.OpUncompress {
+		t.Fatalf("expected a data error from uncompress, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+func TestFlushModes(t *testing.T) {
+	modes := []compression.Flush{
+		compression.PartialFlush,
+		compression.SyncFlush,
+		compression.FullFlush,
+		compression.Block,
+	}
+	for _, mode := range modes {
+		for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeRaw, common.HeaderTypeGzip} {
+			t.Run(fmt.Sprintf("mode:%d h:%d", mode, header), func(t *testing.T) {
+				opts := common.DefaultCompressOptions().WithHeader(header)
+				data := RandBytes(10 + 1<<15)
+
+				var compressed bytes.Buffer
+				compressWriter, err := zlib.NewCompressWriter(&compressed, opts)
+				if err != nil {
+					t.Fatalf("Error creating compress writer: %v", err)
+				}
+				for _, chunk := range [][]byte{data[:1000], data[1000:5000], data[5000:]} {
+					if _, err := compressWriter.Write(chunk); err != nil {
+						t.Fatalf("Error writing to compress writer: %v", err)
+					}
+					if err := compressWriter.(zlib.ModeFlusher).FlushWith(mode); err != nil {
+						t.Fatalf("Error flushing compress writer: %v", err)
+					}
+				}
+				if err := compressWriter.Close(); err != nil {
+					t.Fatalf("Error closing compress writer: %v", err)
+				}
+
+				decompressed, err := synchronousDecompressWriter(t, compressed.Bytes(), matchCompressOptions(opts))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(decompressed, data) {
+					t.Fatalf("decompressed data is not equal to the original data")
+				}
+			})
+		}
+	}
+}
+
+func TestFullFlushRestart(t *testing.T) {
+	opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw)
+	first := RandBytes(10 + 1<<15)
+	second := append(first[:1000:1000], RandBytes(1000)...)
+
+	var compressed bytes.Buffer
+	compressWriter, err := zlib.NewCompressWriter(&compressed, opts)
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := compressWriter.Write(first); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := compressWriter.(zlib.ModeFlusher).FlushWith(compression.FullFlush); err != nil {
+		t.Fatalf("Error flushing compress writer: %v", err)
+	}
+	restartPoint := compressed.Len()
+	if _, err := compressWriter.Write(second); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := compressWriter.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	// The data after a full flush does not refer to the data before it.
+	decompressReader, err := zlib.NewDecompressReader(bytes.NewReader(compressed.Bytes()[restartPoint:]), matchCompressOptions(opts))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	decompressed, err := io.ReadAll(decompressReader)
+	if err != nil {
+		t.Fatalf("Error reading from decompress reader: %v", err)
+	}
+	if !bytes.Equal(decompressed, second) {
+		t.Fatalf("decompressed data is not equal to the data after the full flush")
+	}
+}
+
+func TestDecompressorBlockFlush(t *testing.T) {
+	data := RandBytes(10 + 1<<16)
+	opts := common.DefaultCompressOptions().WithBufferSize(1 << 8)
+	compressed, err := synchronousCompressWriter(t, data, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decompressor, err := compression.NewDecompressor(matchCompressOptions(opts))
+	if err != nil {
+		t.Fatalf("Error creating decompressor: %v", err)
+	}
+
+	input := make([]byte, len(compressed), len(compressed)+1)
+	copy(input, compressed)
+	output := make([]byte, 1<<10)
+	var decompressed []byte
+	n, err := decompressor.Feed(input, compression.Block, output)
+	decompressed = append(decompressed, output[:n]...)
+	stops := 1
+	for err == nil && decompressor.CanCallConsume() {
+		n, err = decompressor.Consume(output)
+		decompressed = append(decompressed, output[:n]...)
+		stops++
+	}
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got: %v", err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Fatalf("decompressed data is not equal to the original data")
+	}
+	if stops < 2 {
+		t.Fatalf("Expected inflate to stop at block boundaries")
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestGetDictionary(t *testing.T) {
+	for _, size := range []int{100, 40000} {
+		data := compressibleBytes(size)
+		expected := data[max(0, len(data)-32768):]
+
+		var compressed bytes.Buffer
+		w, err := zlib.NewCompressWriter(&compressed, common.DefaultCompressOptions())
+		if err != nil {
+			t.Fatalf("Error creating compress writer: %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Error writing: %v", err)
+		}
+		dictionary, err := w.(zlib.DictionaryGetter).GetDictionary()
+		if err != nil {
+			t.Fatalf("Error getting the dictionary of the compress writer: %v", err)
+		}
+		if !bytes.Equal(dictionary, expected) {
+			t.Fatalf("expected the compress writer dictionary to hold the last %d bytes written, got %d bytes", len(expected), len(dictionary))
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Error closing compress writer: %v", err)
+		}
+
+		// zlib does not keep a window when a stream is decompressed by a single inflate call.
+		// The compressed data is fed one byte at a time so that it takes many calls.
+		r, err := zlib.NewDecompressReader(iotest.OneByteReader(bytes.NewReader(compressed.Bytes())), common.DefaultDecompressOptions())
+		if err != nil {
+			t.Fatalf("Error creating decompress reader: %v", err)
+		}
+		if _, err := io.ReadAll(r); err != nil {
+			t.Fatalf("Error decompressing: %v", err)
+		}
+		dictionary, err = r.(zlib.DictionaryGetter).GetDictionary()
+		if err != nil {
+			t.Fatalf("Error getting the dictionary of the decompress reader: %v", err)
+		}
+		if !bytes.Equal(dictionary, expected) {
+			t.Fatalf("expected the decompress reader dictionary to hold the last %d bytes decompressed, got %d bytes", len(expected), len(dictionary))
+		}
+	}
+}
+
+func TestGetDictionaryAsNextDictionary(t *testing.T) {
+	first := []byte(`{"event":"login","user":"someone","client":"web","region":"eu-west-1"}`)
+	second := []byte(`{"event":"logout","user":"someone","client":"web","region":"eu-west-1"}`)
+
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, common.DefaultCompressOptions())
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := w.Write(first); err != nil {
+		t.Fatalf("Error writing: %v", err)
+	}
+	dictionary, err := w.(zlib.DictionaryGetter).GetDictionary()
+	if err != nil {
+		t.Fatalf("Error getting the dictionary: %v", err)
+	}
+	w.Close()
+
+	seeded, err := zlib.Compress(nil, second, common.DefaultCompressOptions().WithInitialDictionary(dictionary))
+	if err != nil {
+		t.Fatalf("Error compressing with the dictionary: %v", err)
+	}
+	unseeded, err := zlib.Compress(nil, second, common.DefaultCompressOptions())
+	if err != nil {
+		t.Fatalf("Error compressing without the dictionary: %v", err)
+	}
+	if len(seeded) >= len(unseeded) {
+		t.Fatalf("expected the dictionary of the previous message to help, got %d bytes with it and %d without", len(seeded), len(unseeded))
+	}
+
+	decompressed, _, err := zlib.Uncompress(nil, seeded, common.DefaultDecompressOptions().WithInitialDictionary(first))
+	if err != nil {
+		t.Fatalf("Error decompressing with the previous message as dictionary: %v", err)
+	}
+	if !bytes.Equal(decompressed, second) {
+		t.Fatalf("decompressed data does not match the second message")
+	}
+}
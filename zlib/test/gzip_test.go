@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestGzipHeaderCompressWriterStdReader(t *testing.T) {
+	data := RandBytes(10 + 1<<16)
+	header := &common.GzipHeader{
+		ModTime: time.Unix(1700000000, 0),
+		OS:      3,
+		Extra:   []byte("extra field"),
+		Name:    "data.txt",
+		Comment: "some comment",
+		HCRC:    true,
+	}
+	opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(header)
+
+	var buf bytes.Buffer
+	compressWriter, err := zlib.NewCompressWriter(&buf, opts)
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := compressWriter.Write(data); err != nil && err != io.EOF {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := compressWriter.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	gzipReader, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Error creating gzip reader: %v", err)
+	}
+	decompressed, err := io.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatalf("Error reading from gzip reader: %v", err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Fatalf("decompressed data is not equal to the original data")
+	}
+
+	if gzipReader.Name != header.Name || gzipReader.Comment != header.Comment || !bytes.Equal(gzipReader.Extra, header.Extra) || !gzipReader.ModTime.Equal(header.ModTime) || gzipReader.OS != header.OS {
+		t.Fatalf("Unexpected gzip header: %+v", gzipReader.Header)
+	}
+}
+
+func TestGzipHeaderStdWriterDecompressReader(t *testing.T) {
+	data := RandBytes(10 + 1<<16)
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	gzipWriter.Name = "data.txt"
+	gzipWriter.Comment = "some comment"
+	gzipWriter.Extra = []byte("extra field")
+	gzipWriter.ModTime = time.Unix(1700000000, 0)
+	if _, err := gzipWriter.Write(data); err != nil {
+		t.Fatalf("Error writing to gzip writer: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("Error closing gzip writer: %v", err)
+	}
+
+	opts := common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip)
+	decompressReader, err := zlib.NewDecompressReader(&buf, opts)
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+
+	if _, ok := decompressReader.(common.GzipHeaderGetter).GzipHeader(); ok {
+		t.Fatalf("The gzip header should not be available before reading")
+	}
+
+	decompressed, err := io.ReadAll(decompressReader)
+	if err != nil {
+		t.Fatalf("Error reading from decompress reader: %v", err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Fatalf("decompressed data is not equal to the original data")
+	}
+
+	header, ok := decompressReader.(common.GzipHeaderGetter).GzipHeader()
+	if !ok {
+		t.Fatalf("The gzip header should be available after reading")
+	}
+	if header.Name != gzipWriter.Name || header.Comment != gzipWriter.Comment || !bytes.Equal(header.Extra, gzipWriter.Extra) || !header.ModTime.Equal(gzipWriter.ModTime) || header.OS != gzipWriter.OS {
+		t.Fatalf("Unexpected gzip header: %+v", header)
+	}
+}
+
+func TestGzipRoundTrip(t *testing.T) {
+	for _, sample := range getDataSamples() {
+		t.Run(sample.name, func(t *testing.T) {
+			compressOpts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(&common.GzipHeader{Name: sample.name})
+			decompressOpts := common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip)
+			err := CompressorWriterDecompressReader(sample.decompressed, compressOpts, func(common.CompressOptions) common.DecompressOptions { return decompressOpts }, t)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGzipHeaderDefaults(t *testing.T) {
+	opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(&common.GzipHeader{Name: "data.txt"})
+	compressed, err := zlib.Compress(nil, []byte("Hello World!"), opts)
+	if err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Error creating gzip reader: %v", err)
+	}
+	if gzipReader.OS != common.GzipOSUnknown || !gzipReader.ModTime.IsZero() {
+		t.Fatalf("expected an unknown OS and no modification time, got %d and %v", gzipReader.OS, gzipReader.ModTime)
+	}
+
+	for _, modTime := range []time.Time{time.Unix(-1, 0), time.Unix(1<<32, 0)} {
+		opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(&common.GzipHeader{ModTime: modTime})
+		if _, err := zlib.NewCompressWriter(io.Discard, opts); err == nil {
+			t.Fatalf("expected an error for the modification time %v", modTime)
+		}
+	}
+}
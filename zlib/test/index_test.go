@@ -0,0 +1,121 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/index"
+)
+
+func TestIndex(t *testing.T) {
+	data := compressibleBytes(4 << 20)
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeGzip, common.HeaderTypeRaw} {
+		t.Run(fmt.Sprintf("h:%d", header), func(t *testing.T) {
+			compressed := compressWith(t, data, common.DefaultCompressOptions().WithHeader(header).WithBufferSize(1<<16))
+
+			buildHeader := header
+			if header != common.HeaderTypeRaw {
+				buildHeader = common.HeaderTypeAuto
+			}
+			idx, err := index.Build(bytes.NewReader(compressed), buildHeader, 256<<10)
+			if err != nil {
+				t.Fatalf("Error building index: %v", err)
+			}
+			if idx.Header != header {
+				t.Fatalf("expected header %v, got %v", header, idx.Header)
+			}
+			if idx.UncompressedSize != int64(len(data)) {
+				t.Fatalf("expected uncompressed size %d, got %d", len(data), idx.UncompressedSize)
+			}
+			if len(idx.Points) < 8 {
+				t.Fatalf("expected at least 8 checkpoints, got %d", len(idx.Points))
+			}
+
+			serialized, err := idx.MarshalBinary()
+			if err != nil {
+				t.Fatalf("Error marshaling index: %v", err)
+			}
+			var loaded index.Index
+			if err := loaded.UnmarshalBinary(serialized); err != nil {
+				t.Fatalf("Error unmarshaling index: %v", err)
+			}
+
+			r := index.NewReader(bytes.NewReader(compressed), &loaded)
+			defer r.Close()
+			for i := 0; i < 20; i++ {
+				off := rand.Int63n(int64(len(data)))
+				p := make([]byte, 1+rand.Intn(100<<10))
+				n, err := r.ReadAt(p, off)
+				end := off + int64(len(p))
+				if end > int64(len(data)) {
+					end = int64(len(data))
+					if err != io.EOF {
+						t.Fatalf("expected io.EOF when reading past the end, got %v", err)
+					}
+				} else if err != nil {
+					t.Fatalf("Error reading at %d: %v", off, err)
+				}
+				if !bytes.Equal(p[:n], data[off:end]) {
+					t.Fatalf("data read at %d is not equal to the original data", off)
+				}
+			}
+
+			off := int64(len(data)) / 3
+			if _, err := r.Seek(off, io.SeekStart); err != nil {
+				t.Fatalf("Error seeking: %v", err)
+			}
+			rest, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Error reading after seek: %v", err)
+			}
+			if !bytes.Equal(rest, data[off:]) {
+				t.Fatalf("data read after seek is not equal to the original data")
+			}
+		})
+	}
+}
+
+func TestIndexInvalid(t *testing.T) {
+	var idx index.Index
+	if err := idx.UnmarshalBinary([]byte("not an index")); err == nil {
+		t.Fatalf("expected an error when unmarshaling an invalid index")
+	}
+
+	compressed := compressWith(t, compressibleBytes(1<<16), common.DefaultCompressOptions())
+	if _, err := index.Build(bytes.NewReader(compressed[:len(compressed)/2]), common.HeaderTypeZlib, 1<<10); err == nil {
+		t.Fatalf("expected an error when building an index of a truncated stream")
+	}
+
+	built, err := index.Build(bytes.NewReader(compressed), common.HeaderTypeZlib, 1<<10)
+	if err != nil {
+		t.Fatalf("Error building index: %v", err)
+	}
+	withoutStart := *built
+	withoutStart.Points = built.Points[1:]
+	unsorted := *built
+	unsorted.Points = append([]index.Point{built.Points[0]}, built.Points[2], built.Points[1])
+	for name, idx := range map[string]*index.Index{
+		"no points":     {Header: common.HeaderTypeZlib, CompressedSize: int64(len(compressed))},
+		"without start": &withoutStart,
+		"unsorted":      &unsorted,
+	} {
+		// A Reader over an invalid index returns an error instead of panicking.
+		if name != "unsorted" {
+			if _, err := index.NewReader(bytes.NewReader(compressed), idx).ReadAt(make([]byte, 10), 0); err == nil {
+				t.Fatalf("%s: expected an error when reading with an index without a checkpoint at 0", name)
+			}
+		}
+		serialized, err := idx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: Error marshaling index: %v", name, err)
+		}
+		var loaded index.Index
+		if err := loaded.UnmarshalBinary(serialized); err == nil {
+			t.Fatalf("%s: expected an error when unmarshaling the index", name)
+		}
+	}
+}
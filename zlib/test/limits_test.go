@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func compressWithOptions(t *testing.T, data []byte, opts common.CompressOptions) []byte {
+	compressed, err := zlib.Compress(nil, data, opts)
+	if err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	return compressed
+}
+
+func expectLimitError(t *testing.T, err error, limit common.Limit) {
+	t.Helper()
+	var limitErr *zlib.LimitError
+	if !errors.Is(err, zlib.ErrLimitExceeded) || !errors.As(err, &limitErr) || limitErr.Limit != limit {
+		t.Fatalf("expected a limit error for the %s, got %v", limit, err)
+	}
+}
+
+func TestMaxOutputSize(t *testing.T) {
+	data := compressibleBytes(100000)
+	compressed := compressWithOptions(t, data, common.DefaultCompressOptions())
+
+	output, _, err := zlib.Uncompress(nil, compressed, common.DefaultDecompressOptions().WithMaxOutputSize(int64(len(data))))
+	if err != nil || !bytes.Equal(output, data) {
+		t.Fatalf("expected decompression at the limit to succeed, got %v", err)
+	}
+
+	limit := len(data) / 2
+	output, _, err = zlib.Uncompress(nil, compressed, common.DefaultDecompressOptions().WithMaxOutputSize(int64(limit)))
+	expectLimitError(t, err, common.LimitOutputSize)
+	if !bytes.Equal(output, data[:limit]) {
+		t.Fatalf("expected the output up to the limit, got %d bytes", len(output))
+	}
+
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions().WithMaxOutputSize(int64(limit)))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	defer r.Close()
+	output, err = io.ReadAll(r)
+	expectLimitError(t, err, common.LimitOutputSize)
+	if !bytes.Equal(output, data[:limit]) {
+		t.Fatalf("expected the output up to the limit, got %d bytes", len(output))
+	}
+
+	// The limit applies to all the members together.
+	members := append(append([]byte(nil), compressed...), compressed...)
+	_, _, err = zlib.Uncompress(nil, members, common.DefaultDecompressOptions().WithMultiMember(true).WithMaxOutputSize(int64(len(data)+1)))
+	expectLimitError(t, err, common.LimitOutputSize)
+}
+
+func TestMaxRatio(t *testing.T) {
+	// A small payload inflating into a lot of zeros.
+	bomb := compressWithOptions(t, make([]byte, 10<<20), common.DefaultCompressOptions().WithLevel(9))
+
+	r, err := zlib.NewDecompressReader(bytes.NewReader(bomb), common.DefaultDecompressOptions().WithMaxRatio(100))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	defer r.Close()
+	output, err := io.ReadAll(r)
+	expectLimitError(t, err, common.LimitRatio)
+	if len(output) > 1<<20 {
+		t.Fatalf("expected decompression to stop early, got %d bytes", len(output))
+	}
+
+	_, _, err = zlib.Uncompress(nil, bomb, common.DefaultDecompressOptions().WithMaxRatio(2000))
+	if err != nil {
+		t.Fatalf("expected the ratio to be below the limit, got %v", err)
+	}
+
+	// Small streams are not checked.
+	small := compressWithOptions(t, make([]byte, 1000), common.DefaultCompressOptions())
+	_, _, err = zlib.Uncompress(nil, small, common.DefaultDecompressOptions().WithMaxRatio(2))
+	if err != nil {
+		t.Fatalf("expected a small stream to be accepted, got %v", err)
+	}
+}
+
+func TestMaxWindowBits(t *testing.T) {
+	data := compressibleBytes(100000)
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeAuto} {
+		large := compressWithOptions(t, data, common.DefaultCompressOptions().WithWindowBits(15))
+		_, _, err := zlib.Uncompress(nil, large, common.DefaultDecompressOptions().WithHeader(header).WithMaxWindowBits(10))
+		expectLimitError(t, err, common.LimitWindowBits)
+
+		small := compressWithOptions(t, data, common.DefaultCompressOptions().WithWindowBits(10))
+		output, _, err := zlib.Uncompress(nil, small, common.DefaultDecompressOptions().WithHeader(header).WithMaxWindowBits(10))
+		if err != nil || !bytes.Equal(output, data) {
+			t.Fatalf("expected a stream with an allowed window to be decompressed, got %v", err)
+		}
+	}
+
+	// zlib uses the window declared by the header when the window bits are 0.
+	large := compressWithOptions(t, data, common.DefaultCompressOptions().WithWindowBits(15))
+	_, _, err := zlib.Uncompress(nil, large, common.DefaultDecompressOptions().WithWindowBits(0).WithMaxWindowBits(10))
+	expectLimitError(t, err, common.LimitWindowBits)
+
+	// A raw deflate stream does not declare its window. Referring further back than the limit is an error.
+	far := append(RandBytes(4000), RandBytes(4000)...)
+	copy(far[len(far)-1000:], far[:1000])
+	raw := compressWithOptions(t, far, common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw).WithWindowBits(15))
+	_, _, err = zlib.Uncompress(nil, raw, common.DefaultDecompressOptions().WithHeader(common.HeaderTypeRaw).WithMaxWindowBits(9))
+	if !errors.Is(err, zlib.ErrData) {
+		t.Fatalf("expected ErrData, got %v", err)
+	}
+
+	// A gzip stream does not declare its window either. The limit applies with the default window bits.
+	gzipped := compressWithOptions(t, far, common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithWindowBits(15))
+	for _, opts := range []common.DecompressOptions{
+		common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip),
+		common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip).WithWindowBits(0),
+		common.DefaultDecompressOptions().WithHeader(common.HeaderTypeAuto).WithWindowBits(0),
+	} {
+		_, _, err = zlib.Uncompress(nil, gzipped, opts.WithMaxWindowBits(9))
+		if !errors.Is(err, zlib.ErrData) {
+			t.Fatalf("expected ErrData for a gzip stream with window bits %d and header %v, got %v", opts.WindowBits(), opts.Header(), err)
+		}
+	}
+}
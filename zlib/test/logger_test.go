@@ -0,0 +1,85 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+// logRecords decodes the records written by a slog.JSONHandler.
+func logRecords(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Error decoding log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func debugLogger(logs *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLogger(t *testing.T) {
+	data := compressibleBytes(10000)
+	var logs bytes.Buffer
+	compressed, err := zlib.Compress(nil, data, common.DefaultCompressOptions().WithLogger(debugLogger(&logs)))
+	if err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	_, _, err = zlib.Uncompress(nil, compressed, common.DefaultDecompressOptions().WithLogger(debugLogger(&logs)))
+	if err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+
+	ops := map[string]int{}
+	for _, record := range logRecords(t, &logs) {
+		if record["msg"] != "zlib call" {
+			continue
+		}
+		for _, key := range []string{"stream", "flush", "ret", "avail_in", "avail_out"} {
+			if _, ok := record[key]; !ok {
+				t.Fatalf("expected %s in %v", key, record)
+			}
+		}
+		ops[record["op"].(string)]++
+	}
+	if ops["deflate"] == 0 || ops["inflate"] == 0 {
+		t.Fatalf("expected deflate and inflate calls, got %v", ops)
+	}
+}
+
+func TestGlobalLogger(t *testing.T) {
+	defer zlib.SetLogger(nil)
+	data := compressibleBytes(10000)
+
+	var logs bytes.Buffer
+	zlib.SetLogger(debugLogger(&logs))
+	if _, err := zlib.Compress(nil, data, common.DefaultCompressOptions().WithLevel(6)); err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	if len(logRecords(t, &logs)) == 0 {
+		t.Fatalf("expected records in the global logger")
+	}
+
+	// A logger that is not enabled for debug messages does not get any record.
+	logs.Reset()
+	zlib.SetLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+	if _, err := zlib.Compress(nil, data, common.DefaultCompressOptions().WithLevel(6)); err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected no records, got %s", logs.String())
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestMultiMember(t *testing.T) {
+	parts := [][]byte{compressibleBytes(1 << 16), RandBytes(100), {}, compressibleBytes(3000)}
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeGzip, common.HeaderTypeRaw} {
+		var members [][]byte
+		for i, part := range parts {
+			opts := common.DefaultCompressOptions().WithHeader(header)
+			if header == common.HeaderTypeGzip {
+				opts.WithGzipHeader(&common.GzipHeader{Name: fmt.Sprintf("part%d", i)})
+			}
+			members = append(members, compressWith(t, part, opts))
+		}
+		compressed := bytes.Join(members, nil)
+		data := bytes.Join(parts, nil)
+
+		decompressHeaders := []common.HeaderType{header}
+		if header != common.HeaderTypeRaw {
+			decompressHeaders = append(decompressHeaders, common.HeaderTypeAuto)
+		}
+		for _, decompressHeader := range decompressHeaders {
+			for _, bufferSize := range []int{64, 1024, 1 << 20} {
+				t.Run(fmt.Sprintf("h:%d/dh:%d/b:%d", header, decompressHeader, bufferSize), func(t *testing.T) {
+					var got []common.Member
+					opts := func() common.DecompressOptions {
+						got = nil
+						return common.DefaultDecompressOptions().
+							WithHeader(decompressHeader).
+							WithBufferSize(bufferSize).
+							WithMultiMember(true).
+							WithMemberHandler(func(member common.Member) {
+								got = append(got, member)
+							})
+					}
+
+					r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), opts())
+					if err != nil {
+						t.Fatalf("Error creating decompress reader: %v", err)
+					}
+					decompressed, err := io.ReadAll(r)
+					if err != nil {
+						t.Fatalf("Error reading from decompress reader: %v", err)
+					}
+					if !bytes.Equal(decompressed, data) {
+						t.Fatalf("decompressed data is not equal to the original data")
+					}
+					checkMembers(t, got, parts, members, header)
+
+					// Write the members one by one so that the input ends right at the end of each member.
+					var buf bytes.Buffer
+					w, err := zlib.NewDecompressWriter(&buf, opts())
+					if err != nil {
+						t.Fatalf("Error creating decompress writer: %v", err)
+					}
+					for _, member := range members {
+						if _, err := w.Write(member); err != nil {
+							t.Fatalf("Error writing to decompress writer: %v", err)
+						}
+					}
+					if err := w.Close(); err != nil {
+						t.Fatalf("Error closing decompress writer: %v", err)
+					}
+					if !bytes.Equal(buf.Bytes(), data) {
+						t.Fatalf("decompressed data is not equal to the original data")
+					}
+					checkMembers(t, got, parts, members, header)
+				})
+			}
+		}
+	}
+}
+
+func checkMembers(t *testing.T, got []common.Member, parts [][]byte, members [][]byte, header common.HeaderType) {
+	if len(got) != len(parts) {
+		t.Fatalf("expected %d members, got %d", len(parts), len(got))
+	}
+	for i, member := range got {
+		if member.Header != header {
+			t.Fatalf("member %d: expected header %v, got %v", i, header, member.Header)
+		}
+		if member.CompressedSize != int64(len(members[i])) || member.UncompressedSize != int64(len(parts[i])) {
+			t.Fatalf("member %d: unexpected sizes %d/%d, expected %d/%d", i, member.CompressedSize, member.UncompressedSize, len(members[i]), len(parts[i]))
+		}
+		var checksum uint32
+		switch header {
+		case common.HeaderTypeZlib:
+			checksum = adler32.Checksum(parts[i])
+		case common.HeaderTypeGzip:
+			checksum = crc32.ChecksumIEEE(parts[i])
+			if member.GzipHeader == nil || member.GzipHeader.Name != fmt.Sprintf("part%d", i) {
+				t.Fatalf("member %d: unexpected gzip header %+v", i, member.GzipHeader)
+			}
+		}
+		if member.Checksum != checksum {
+			t.Fatalf("member %d: expected checksum %x, got %x", i, checksum, member.Checksum)
+		}
+	}
+}
+
+func TestMultiMemberDisabled(t *testing.T) {
+	second := compressWith(t, []byte("World!"), common.DefaultCompressOptions())
+	compressed := append(compressWith(t, []byte("Hello "), common.DefaultCompressOptions()), second...)
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions())
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+	if string(output) != "Hello " {
+		t.Fatalf("expected only the first stream, got %q", output)
+	}
+	if remaining := r.(interface{ Remaining() []byte }).Remaining(); !bytes.Equal(remaining, second) {
+		t.Fatalf("expected the second stream to remain, got %d bytes", len(remaining))
+	}
+}
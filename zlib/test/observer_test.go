@@ -0,0 +1,198 @@
+package test
+
+import (
+	"bytes"
+	"expvar"
+	"io"
+	"runtime/trace"
+	"sync"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/capi"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/observer"
+)
+
+type recordingObserver struct {
+	mu         sync.Mutex
+	inits      map[common.Direction]int
+	zlibCalls  map[capi.Op]int
+	calls      map[common.Direction]int
+	bytesIn    map[common.Direction]int
+	bytesOut   map[common.Direction]int
+	flushes    map[common.Direction]int
+	streamEnds map[common.Direction][]common.Stats
+	errors     map[common.Direction][]error
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{
+		inits:      map[common.Direction]int{},
+		zlibCalls:  map[capi.Op]int{},
+		calls:      map[common.Direction]int{},
+		bytesIn:    map[common.Direction]int{},
+		bytesOut:   map[common.Direction]int{},
+		flushes:    map[common.Direction]int{},
+		streamEnds: map[common.Direction][]common.Stats{},
+		errors:     map[common.Direction][]error{},
+	}
+}
+
+func (o *recordingObserver) StreamInit(direction common.Direction) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.inits[direction]++
+}
+
+func (o *recordingObserver) ZlibCall(direction common.Direction, op capi.Op) func() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.zlibCalls[op]++
+	return nil
+}
+
+func (o *recordingObserver) Feed(call common.Call) {
+	o.record(call)
+}
+
+func (o *recordingObserver) Consume(call common.Call) {
+	o.record(call)
+}
+
+func (o *recordingObserver) record(call common.Call) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls[call.Direction]++
+	o.bytesIn[call.Direction] += call.BytesIn
+	o.bytesOut[call.Direction] += call.BytesOut
+}
+
+func (o *recordingObserver) Flush(direction common.Direction) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.flushes[direction]++
+}
+
+func (o *recordingObserver) StreamEnd(direction common.Direction, stats common.Stats) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.streamEnds[direction] = append(o.streamEnds[direction], stats)
+}
+
+func (o *recordingObserver) Error(direction common.Direction, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errors[direction] = append(o.errors[direction], err)
+}
+
+func observedRoundTrip(t *testing.T, data []byte, obs common.Observer) []byte {
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, common.DefaultCompressOptions().WithObserver(obs))
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	w.Write(data[:len(data)/2])
+	w.Flush()
+	w.Write(data[len(data)/2:])
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed.Bytes()), common.DefaultDecompressOptions().WithObserver(obs))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+	r.Close()
+	if !bytes.Equal(output, data) {
+		t.Fatalf("decompressed data does not match")
+	}
+	return compressed.Bytes()
+}
+
+func TestObserver(t *testing.T) {
+	data := compressibleBytes(100000)
+	obs := newRecordingObserver()
+	compressed := observedRoundTrip(t, data, obs)
+
+	for _, direction := range []common.Direction{common.DirectionCompress, common.DirectionDecompress} {
+		if obs.inits[direction] != 1 {
+			t.Fatalf("%v: expected 1 stream init, got %d", direction, obs.inits[direction])
+		}
+		if len(obs.streamEnds[direction]) != 1 || len(obs.errors[direction]) != 0 {
+			t.Fatalf("%v: expected 1 stream end and no errors, got %v and %v", direction, obs.streamEnds[direction], obs.errors[direction])
+		}
+		if obs.calls[direction] == 0 {
+			t.Fatalf("%v: expected calls into zlib", direction)
+		}
+		stats := obs.streamEnds[direction][0]
+		if int64(obs.bytesIn[direction]) != stats.TotalIn || int64(obs.bytesOut[direction]) != stats.TotalOut {
+			t.Fatalf("%v: calls report %d and %d bytes, stats report %+v", direction, obs.bytesIn[direction], obs.bytesOut[direction], stats)
+		}
+	}
+	if obs.bytesIn[common.DirectionCompress] != len(data) || obs.bytesOut[common.DirectionCompress] != len(compressed) {
+		t.Fatalf("expected compression of %d bytes into %d, got %d into %d", len(data), len(compressed), obs.bytesIn[common.DirectionCompress], obs.bytesOut[common.DirectionCompress])
+	}
+	if obs.flushes[common.DirectionCompress] != 2 {
+		t.Fatalf("expected a flush and the final one, got %d", obs.flushes[common.DirectionCompress])
+	}
+	if obs.zlibCalls[capi.OpDeflate] != obs.calls[common.DirectionCompress] || obs.zlibCalls[capi.OpInflate] != obs.calls[common.DirectionDecompress] {
+		t.Fatalf("expected a zlib call for each call, got %v and %v", obs.zlibCalls, obs.calls)
+	}
+}
+
+func TestObserverError(t *testing.T) {
+	obs := newRecordingObserver()
+	corrupted, err := zlib.Compress(nil, compressibleBytes(1000), common.DefaultCompressOptions())
+	if err != nil {
+		t.Fatalf("Error compressing: %v", err)
+	}
+	corrupted[0] ^= 0xff
+	_, _, err = zlib.Uncompress(nil, corrupted, common.DefaultDecompressOptions().WithObserver(obs))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if len(obs.errors[common.DirectionDecompress]) != 1 || len(obs.streamEnds[common.DirectionDecompress]) != 0 {
+		t.Fatalf("expected 1 error and no stream end, got %v and %v", obs.errors, obs.streamEnds)
+	}
+}
+
+func TestExpvarObserver(t *testing.T) {
+	obs := observer.NewExpvar("zlib_test_observer")
+	data := compressibleBytes(100000)
+
+	// The regions are only created while tracing.
+	if err := trace.Start(io.Discard); err != nil {
+		t.Fatalf("Error starting trace: %v", err)
+	}
+	compressed := observedRoundTrip(t, data, obs)
+	trace.Stop()
+
+	value := func(direction common.Direction, name string) int64 {
+		return obs.Vars().Get(direction.String()).(*expvar.Map).Get(name).(*expvar.Int).Value()
+	}
+	expected := map[common.Direction]map[string]int64{
+		common.DirectionCompress: {
+			"streams": 1, "stream_ends": 1, "errors": 0, "flushes": 2,
+			"bytes_in": int64(len(data)), "bytes_out": int64(len(compressed)),
+		},
+		common.DirectionDecompress: {
+			"streams": 1, "stream_ends": 1, "errors": 0,
+			"bytes_in": int64(len(compressed)), "bytes_out": int64(len(data)),
+		},
+	}
+	for direction, vars := range expected {
+		for name, v := range vars {
+			if got := value(direction, name); got != v {
+				t.Fatalf("%v %s: expected %d, got %d", direction, name, v, got)
+			}
+		}
+		if value(direction, "calls") == 0 || value(direction, "zlib_ns") == 0 {
+			t.Fatalf("%v: expected calls and time spent in zlib", direction)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestCompressUncompress(t *testing.T) {
+	compressOptions := map[string]common.CompressOptions{
+		"compress2": common.DefaultCompressOptions().WithLevel(6).WithMemoryLevel(8),
+		"default":   common.DefaultCompressOptions(),
+		"gzip":      common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip),
+		"raw":       common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw).WithWindowBits(10),
+	}
+	for name, opts := range compressOptions {
+		for _, size := range []int{0, 1, 100, 100000} {
+			for _, data := range [][]byte{compressibleBytes(size), RandBytes(size)} {
+				prefix := []byte("prefix")
+				compressed, err := zlib.Compress(prefix, data, opts)
+				if err != nil {
+					t.Fatalf("%s: Error compressing %d bytes: %v", name, size, err)
+				}
+				if !bytes.HasPrefix(compressed, prefix) {
+					t.Fatalf("%s: the compressed data was not appended to dst", name)
+				}
+				compressed = compressed[len(prefix):]
+
+				decompressed, err := stdDecompress(compressed, opts)
+				if err != nil {
+					t.Fatalf("%s: Error decompressing %d bytes with the standard library: %v", name, size, err)
+				}
+				if !bytes.Equal(decompressed, data) {
+					t.Fatalf("%s: decompressed data does not match the input of %d bytes", name, size)
+				}
+
+				decompressOpts := common.DefaultDecompressOptions().WithHeader(opts.Header()).WithWindowBits(opts.WindowBits())
+				for _, dst := range [][]byte{nil, make([]byte, 0, size+10), make([]byte, 3, 5)} {
+					trailing := append(append([]byte(nil), compressed...), "trailing"...)
+					uncompressed, consumed, err := zlib.Uncompress(dst, trailing, decompressOpts)
+					if err != nil {
+						t.Fatalf("%s: Error uncompressing %d bytes: %v", name, size, err)
+					}
+					if consumed != len(compressed) {
+						t.Fatalf("%s: expected %d bytes to be consumed, got %d", name, len(compressed), consumed)
+					}
+					if !bytes.Equal(uncompressed[:len(dst)], dst) || !bytes.Equal(uncompressed[len(dst):], data) {
+						t.Fatalf("%s: uncompressed data does not match the input of %d bytes", name, size)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestUncompressErrors(t *testing.T) {
+	data := compressibleBytes(10000)
+	for _, opts := range []common.DecompressOptions{
+		common.DefaultDecompressOptions(),
+		common.DefaultDecompressOptions().WithHeader(common.HeaderTypeAuto),
+	} {
+		compressed, err := zlib.Compress(nil, data, common.DefaultCompressOptions())
+		if err != nil {
+			t.Fatalf("Error compressing: %v", err)
+		}
+		if _, _, err := zlib.Uncompress(nil, compressed[:len(compressed)/2], opts); err == nil {
+			t.Fatalf("expected an error for truncated data")
+		}
+		compressed[len(compressed)/2] ^= 0xff
+		if _, _, err := zlib.Uncompress(nil, compressed, opts); err == nil {
+			t.Fatalf("expected an error for corrupted data")
+		}
+	}
+}
+
+func TestCompressUncompressFullCapacity(t *testing.T) {
+	compressOptions := map[string]common.CompressOptions{
+		"gzip":    common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip),
+		"raw":     common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw),
+		"context": common.DefaultCompressOptions().WithContext(context.Background()),
+	}
+	for name, opts := range compressOptions {
+		for _, size := range []int{0, 1, 100, 300000} {
+			for _, data := range [][]byte{compressibleBytes(size), RandBytes(size)} {
+				// src has no spare capacity after its data.
+				compressed, err := zlib.Compress(nil, data[:size:size], opts)
+				if err != nil {
+					t.Fatalf("%s: Error compressing %d bytes: %v", name, size, err)
+				}
+				runtime.GC()
+
+				decompressed, err := stdDecompress(compressed, opts)
+				if err != nil {
+					t.Fatalf("%s: Error decompressing %d bytes with the standard library: %v", name, size, err)
+				}
+				if !bytes.Equal(decompressed, data) {
+					t.Fatalf("%s: decompressed data does not match the input of %d bytes", name, size)
+				}
+
+				decompressOpts := common.DefaultDecompressOptions().WithHeader(opts.Header()).WithContext(opts.Context())
+				trailing := append(append([]byte(nil), compressed...), RandBytes(100000)...)
+				for _, src := range [][]byte{compressed[:len(compressed):len(compressed)], trailing[:len(trailing):len(trailing)]} {
+					uncompressed, consumed, err := zlib.Uncompress(nil, src, decompressOpts)
+					if err != nil {
+						t.Fatalf("%s: Error uncompressing %d bytes: %v", name, size, err)
+					}
+					runtime.GC()
+					if consumed != len(compressed) {
+						t.Fatalf("%s: expected %d bytes to be consumed, got %d", name, len(compressed), consumed)
+					}
+					if !bytes.Equal(uncompressed, data) {
+						t.Fatalf("%s: uncompressed data does not match the input of %d bytes", name, size)
+					}
+				}
+			}
+		}
+	}
+}
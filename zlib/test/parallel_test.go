@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	stdzlib "compress/zlib"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestParallelCompressWriter(t *testing.T) {
+	optsList := []common.CompressOptions{
+		common.DefaultCompressOptions(),
+		common.DefaultCompressOptions().WithLevel(9).WithWindowBits(9),
+		common.DefaultCompressOptions().WithLevel(-1).WithStrategy(common.StrategyHuffmanOnly),
+		common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw),
+		common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip),
+		common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithLevel(9).WithGzipHeader(&common.GzipHeader{Name: "parallel", Comment: "blocks", Extra: []byte("x"), HCRC: true, OS: 3}),
+		common.DefaultCompressOptions().WithInitialDictionary([]byte("This is a dictionary")),
+	}
+	blockSizes := []int{1000, 1 << 12, 0}
+
+	for _, sample := range getDataSamples() {
+		for _, opts := range optsList {
+			for _, blockSize := range blockSizes {
+				name := fmt.Sprintf("%s l:%d w:%d h:%d dict:%d block:%d", sample.name, opts.Level(), opts.WindowBits(), opts.Header(), len(opts.InitialDictionary()), blockSize)
+				t.Run(name, func(t *testing.T) {
+					var compressed bytes.Buffer
+					w, err := zlib.NewParallelCompressWriter(&compressed, opts, blockSize, 4)
+					if err != nil {
+						t.Fatalf("Error creating parallel compress writer: %v", err)
+					}
+					half := len(sample.decompressed) / 2
+					if _, err := w.Write(sample.decompressed[:half]); err != nil {
+						t.Fatalf("Error writing to parallel compress writer: %v", err)
+					}
+					if err := w.Flush(); err != nil {
+						t.Fatalf("Error flushing parallel compress writer: %v", err)
+					}
+					if _, err := w.Write(sample.decompressed[half:]); err != nil {
+						t.Fatalf("Error writing to parallel compress writer: %v", err)
+					}
+					if err := w.Close(); err != nil {
+						t.Fatalf("Error closing parallel compress writer: %v", err)
+					}
+
+					decompressed, err := synchronousDecompressWriter(t, compressed.Bytes(), matchCompressOptions(opts))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(decompressed, sample.decompressed) {
+						t.Fatalf("decompressed data is not equal to the original data")
+					}
+
+					decompressed, err = stdDecompress(compressed.Bytes(), opts)
+					if err != nil {
+						t.Fatalf("Error decompressing with the standard library: %v", err)
+					}
+					if !bytes.Equal(decompressed, sample.decompressed) {
+						t.Fatalf("decompressed data by the standard library is not equal to the original data")
+					}
+				})
+			}
+		}
+	}
+}
+
+func stdDecompress(compressed []byte, opts common.CompressOptions) ([]byte, error) {
+	var r io.Reader
+	var err error
+	switch opts.Header() {
+	case common.HeaderTypeZlib:
+		r, err = stdzlib.NewReaderDict(bytes.NewReader(compressed), opts.InitialDictionary())
+	case common.HeaderTypeGzip:
+		r, err = gzip.NewReader(bytes.NewReader(compressed))
+	case common.HeaderTypeRaw:
+		r = flate.NewReaderDict(bytes.NewReader(compressed), opts.InitialDictionary())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestParallelCompressWriterGzipHeader(t *testing.T) {
+	headers := []*common.GzipHeader{
+		nil,
+		{Name: "parallel"},
+		{ModTime: time.Unix(1700000000, 0), OS: common.GzipOSUnix},
+	}
+	for _, header := range headers {
+		for _, level := range []int{1, 6, 9} {
+			opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithLevel(level).WithGzipHeader(header)
+			var compressed bytes.Buffer
+			w, err := zlib.NewParallelCompressWriter(&compressed, opts, 0, 2)
+			if err != nil {
+				t.Fatalf("Error creating parallel compress writer: %v", err)
+			}
+			w.Write([]byte("Hello World!"))
+			if err := w.Close(); err != nil {
+				t.Fatalf("Error closing parallel compress writer: %v", err)
+			}
+
+			expected, err := zlib.Compress(nil, []byte("Hello World!"), opts)
+			if err != nil {
+				t.Fatalf("Error compressing: %v", err)
+			}
+			// The first 10 bytes hold the flags, modification time, extra flags and OS.
+			if !bytes.Equal(compressed.Bytes()[:10], expected[:10]) {
+				t.Fatalf("level %d: expected the header written by deflate %x, got %x", level, expected[:10], compressed.Bytes()[:10])
+			}
+		}
+	}
+
+	opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(&common.GzipHeader{ModTime: time.Unix(1<<32, 0)})
+	if _, err := zlib.NewParallelCompressWriter(io.Discard, opts, 0, 2); err == nil {
+		t.Fatalf("expected an error for a modification time outside 32 bits")
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+func TestSetParams(t *testing.T) {
+	params := []struct {
+		level    int
+		strategy common.StrategyType
+	}{
+		{9, common.StrategyDefault},
+		{0, common.StrategyDefault},
+		{1, common.StrategyHuffmanOnly},
+		{6, common.StrategyRLE},
+		{-1, common.StrategyFiltered},
+		{4, common.StrategyFixed},
+	}
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeRaw, common.HeaderTypeGzip} {
+		t.Run(fmt.Sprintf("h:%d", header), func(t *testing.T) {
+			opts := common.DefaultCompressOptions().WithHeader(header)
+
+			var data []byte
+			var compressed bytes.Buffer
+			compressWriter, err := zlib.NewCompressWriter(&compressed, opts)
+			if err != nil {
+				t.Fatalf("Error creating compress writer: %v", err)
+			}
+			for _, p := range params {
+				if err := compressWriter.(zlib.ParamsSetter).SetParams(p.level, p.strategy); err != nil {
+					t.Fatalf("Error setting params %+v: %v", p, err)
+				}
+				chunk := RandBytes(10 + 1<<12)
+				data = append(data, chunk...)
+				if _, err := compressWriter.Write(chunk); err != nil {
+					t.Fatalf("Error writing to compress writer: %v", err)
+				}
+			}
+			if err := compressWriter.Close(); err != nil {
+				t.Fatalf("Error closing compress writer: %v", err)
+			}
+
+			decompressed, err := synchronousDecompressWriter(t, compressed.Bytes(), matchCompressOptions(opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Fatalf("decompressed data is not equal to the original data")
+			}
+		})
+	}
+}
+
+func TestCompressorSetParamsRequiresFlush(t *testing.T) {
+	opts := common.DefaultCompressOptions()
+	compressor, err := compression.NewCompressor(opts)
+	if err != nil {
+		t.Fatalf("Error creating compressor: %v", err)
+	}
+
+	data := RandBytes(1000)
+	input := make([]byte, len(data), len(data)+1)
+	copy(input, data)
+	output := make([]byte, 1<<12)
+	var compressed []byte
+
+	n, err := compressor.Feed(input, compression.NoFlush, output)
+	if err != nil {
+		t.Fatalf("Error feeding compressor: %v", err)
+	}
+	compressed = append(compressed, output[:n]...)
+
+	if err := compressor.SetParams(9, common.StrategyDefault); err == nil {
+		t.Fatalf("Expected an error when setting params before flushing")
+	}
+
+	n, err = compressor.Feed(nil, compression.Block, output)
+	if err != nil {
+		t.Fatalf("Error flushing compressor: %v", err)
+	}
+	compressed = append(compressed, output[:n]...)
+
+	if err := compressor.SetParams(9, common.StrategyDefault); err != nil {
+		t.Fatalf("Error setting params after flushing: %v", err)
+	}
+
+	n, err = compressor.Feed(nil, compression.Finish, output)
+	compressed = append(compressed, output[:n]...)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got: %v", err)
+	}
+
+	decompressed, err := synchronousDecompressWriter(t, compressed, matchCompressOptions(opts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Fatalf("decompressed data is not equal to the original data")
+	}
+}
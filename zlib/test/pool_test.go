@@ -0,0 +1,166 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestPool(t *testing.T) {
+	pool := zlib.NewPool()
+	optsList := getResetOptions()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				opts := optsList[(g+i)%len(optsList)]
+				if err := poolRoundTrip(pool, RandBytes(10+1<<12), opts); err != nil {
+					errs <- fmt.Errorf("goroutine %d iteration %d: %w", g, i, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func poolRoundTrip(pool *zlib.Pool, data []byte, opts common.CompressOptions) error {
+	decompressOpts := matchCompressOptions(opts)
+
+	// CompressWriter -> DecompressReader
+	var compressed bytes.Buffer
+	compressWriter, err := pool.GetCompressWriter(&compressed, opts)
+	if err != nil {
+		return fmt.Errorf("Error getting compress writer: %w", err)
+	}
+	if _, err := compressWriter.Write(data); err != nil {
+		return fmt.Errorf("Error writing to compress writer: %w", err)
+	}
+	if err := compressWriter.Close(); err != nil {
+		return fmt.Errorf("Error closing compress writer: %w", err)
+	}
+
+	decompressReader, err := pool.GetDecompressReader(&compressed, decompressOpts)
+	if err != nil {
+		return fmt.Errorf("Error getting decompress reader: %w", err)
+	}
+	decompressed, err := io.ReadAll(decompressReader)
+	if err != nil {
+		return fmt.Errorf("Error reading from decompress reader: %w", err)
+	}
+	if err := decompressReader.Close(); err != nil {
+		return fmt.Errorf("Error closing decompress reader: %w", err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		return fmt.Errorf("decompressed data is not equal to the original data")
+	}
+
+	// CompressReader -> DecompressWriter
+	compressReader, err := pool.GetCompressReader(bytes.NewReader(data), opts)
+	if err != nil {
+		return fmt.Errorf("Error getting compress reader: %w", err)
+	}
+	compressed2, err := io.ReadAll(compressReader)
+	if err != nil {
+		return fmt.Errorf("Error reading from compress reader: %w", err)
+	}
+	if err := compressReader.Close(); err != nil {
+		return fmt.Errorf("Error closing compress reader: %w", err)
+	}
+
+	var decompressed2 bytes.Buffer
+	decompressWriter, err := pool.GetDecompressWriter(&decompressed2, decompressOpts)
+	if err != nil {
+		return fmt.Errorf("Error getting decompress writer: %w", err)
+	}
+	if _, err := decompressWriter.Write(compressed2); err != nil && err != io.EOF {
+		return fmt.Errorf("Error writing to decompress writer: %w", err)
+	}
+	if err := decompressWriter.Close(); err != nil {
+		return fmt.Errorf("Error closing decompress writer: %w", err)
+	}
+	if !bytes.Equal(decompressed2.Bytes(), data) {
+		return fmt.Errorf("decompressed2 data is not equal to the original data")
+	}
+
+	return nil
+}
+
+// finalizedObserver records when it is garbage collected.
+type finalizedObserver struct {
+	common.NopObserver
+	// padding makes sure that the observer is not a tiny allocation whose finalizer may never run.
+	padding [64]byte
+}
+
+func TestPoolDropsOptions(t *testing.T) {
+	pool := zlib.NewPool()
+	compressed := compressWith(t, []byte("Hello World!"), common.DefaultCompressOptions())
+
+	var closers []io.Closer
+	finalized := make(chan struct{}, 4)
+	newObserver := func() common.Observer {
+		observer := &finalizedObserver{}
+		runtime.SetFinalizer(observer, func(*finalizedObserver) { finalized <- struct{}{} })
+		return observer
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	compressOpts := func() common.CompressOptions {
+		return common.DefaultCompressOptions().WithObserver(newObserver()).WithContext(ctx)
+	}
+	decompressOpts := func() common.DecompressOptions {
+		return common.DefaultDecompressOptions().WithObserver(newObserver()).WithContext(ctx)
+	}
+	cw, err := pool.GetCompressWriter(io.Discard, compressOpts())
+	if err != nil {
+		t.Fatalf("Error getting compress writer: %v", err)
+	}
+	cr, err := pool.GetCompressReader(bytes.NewReader(nil), compressOpts())
+	if err != nil {
+		t.Fatalf("Error getting compress reader: %v", err)
+	}
+	dw, err := pool.GetDecompressWriter(io.Discard, decompressOpts())
+	if err != nil {
+		t.Fatalf("Error getting decompress writer: %v", err)
+	}
+	dr, err := pool.GetDecompressReader(bytes.NewReader(compressed), decompressOpts())
+	if err != nil {
+		t.Fatalf("Error getting decompress reader: %v", err)
+	}
+	closers = append(closers, cw, cr, dw, dr)
+	io.ReadAll(cr)
+	dw.Write(compressed)
+	io.ReadAll(dr)
+	for _, closer := range closers {
+		closer.Close()
+	}
+
+	// The closed values are still referenced. Their observers must not be.
+	for i := 0; i < 4; i++ {
+		runtime.GC()
+		select {
+		case <-finalized:
+		case <-time.After(time.Second):
+			t.Fatalf("expected the observers of closed pooled values to be released, %d were", i)
+		}
+	}
+	runtime.KeepAlive(closers)
+}
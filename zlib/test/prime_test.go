@@ -0,0 +1,119 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+func TestPrime(t *testing.T) {
+	data := compressibleBytes(1 << 16)
+	for bits := 1; bits <= 7; bits++ {
+		t.Run(fmt.Sprintf("bits:%d", bits), func(t *testing.T) {
+			value := 0x55 & (1<<bits - 1)
+			opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw)
+
+			var compressed bytes.Buffer
+			w, err := zlib.NewCompressWriter(&compressed, opts)
+			if err != nil {
+				t.Fatalf("Error creating compress writer: %v", err)
+			}
+			if err := w.(zlib.Primer).Prime(bits, value); err != nil {
+				t.Fatalf("Error priming compress writer: %v", err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatalf("Error writing to compress writer: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Error closing compress writer: %v", err)
+			}
+
+			first := int(compressed.Bytes()[0])
+			if first&(1<<bits-1) != value {
+				t.Fatalf("primed bits are not at the start of the output: got %08b, want %0*b", first, bits, value)
+			}
+
+			// Start decompressing in the middle of the first byte, right after the primed bits.
+			r, err := zlib.NewDecompressReader(bytes.NewReader(compressed.Bytes()[1:]), common.DefaultDecompressOptions().WithHeader(common.HeaderTypeRaw))
+			if err != nil {
+				t.Fatalf("Error creating decompress reader: %v", err)
+			}
+			if err := r.(zlib.Primer).Prime(8-bits, first>>bits); err != nil {
+				t.Fatalf("Error priming decompress reader: %v", err)
+			}
+			decompressed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Error reading from decompress reader: %v", err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Fatalf("decompressed data is not equal to the original data")
+			}
+		})
+	}
+}
+
+func TestPrimeErrors(t *testing.T) {
+	w, err := zlib.NewCompressWriter(io.Discard, common.DefaultCompressOptions().WithHeader(common.HeaderTypeZlib))
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if err := w.(zlib.Primer).Prime(3, 1); err == nil {
+		t.Fatalf("expected an error when priming a zlib stream")
+	}
+
+	w, err = zlib.NewCompressWriter(io.Discard, common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw))
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := w.Write([]byte("Hello World!")); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := w.(zlib.Primer).Prime(3, 1); err == nil {
+		t.Fatalf("expected an error when priming after Write")
+	}
+	// The stream is still usable after a rejected Prime.
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	d, err := compression.NewDecompressor(common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip))
+	if err != nil {
+		t.Fatalf("Error creating decompressor: %v", err)
+	}
+	defer d.Close()
+	if err := d.Prime(3, 1); err == nil {
+		t.Fatalf("expected an error when priming a gzip stream")
+	}
+}
+
+func TestPending(t *testing.T) {
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw))
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := w.Write(compressibleBytes(1 << 12)); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := w.(zlib.ModeFlusher).FlushWith(compression.Block); err != nil {
+		t.Fatalf("Error flushing compress writer: %v", err)
+	}
+	pendingBytes, pendingBits, err := w.(zlib.PendingReporter).Pending()
+	if err != nil {
+		t.Fatalf("Error getting pending output: %v", err)
+	}
+	if pendingBytes != 0 || pendingBits < 0 || pendingBits > 7 {
+		t.Fatalf("unexpected pending output after Block flush: %d bytes, %d bits", pendingBytes, pendingBits)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+	if _, _, err := w.(zlib.PendingReporter).Pending(); err == nil {
+		t.Fatalf("expected an error after Close")
+	}
+}
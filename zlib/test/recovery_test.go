@@ -0,0 +1,168 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/compression"
+)
+
+// compressWithFullFlushes compresses the chunks with a full flush between each two chunks.
+// It returns the compressed data and the offset where each chunk starts in the compressed data.
+func compressWithFullFlushes(t *testing.T, chunks [][]byte, opts common.CompressOptions) ([]byte, []int) {
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, opts)
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	var offsets []int
+	for i, chunk := range chunks {
+		if i > 0 {
+			if err := w.(zlib.ModeFlusher).FlushWith(compression.FullFlush); err != nil {
+				t.Fatalf("Error flushing compress writer: %v", err)
+			}
+		}
+		offsets = append(offsets, compressed.Len())
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatalf("Error writing to compress writer: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+	return compressed.Bytes(), offsets
+}
+
+func TestRecovery(t *testing.T) {
+	chunks := make([][]byte, 4)
+	for i := range chunks {
+		chunks[i] = compressibleBytes(1 << 16)
+	}
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeGzip, common.HeaderTypeRaw} {
+		t.Run(fmt.Sprintf("h:%d", header), func(t *testing.T) {
+			compressed, offsets := compressWithFullFlushes(t, chunks, common.DefaultCompressOptions().WithHeader(header))
+
+			// Corrupt the middle of the second chunk.
+			start := offsets[1] + (offsets[2]-offsets[1])/2
+			for i := start; i < start+64; i++ {
+				compressed[i] ^= 0xA5
+			}
+
+			var regions []common.SkippedRegion
+			opts := common.DefaultDecompressOptions().WithHeader(header).WithRecovery(func(region common.SkippedRegion) {
+				regions = append(regions, region)
+			})
+			r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), opts)
+			if err != nil {
+				t.Fatalf("Error creating decompress reader: %v", err)
+			}
+			decompressed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Error reading from decompress reader: %v", err)
+			}
+
+			if len(regions) != 1 {
+				t.Fatalf("expected 1 skipped region, got %d: %+v", len(regions), regions)
+			}
+			region := regions[0]
+			if region.Offset < int64(start) || region.Offset+region.Length > int64(offsets[2]) || region.Err == nil {
+				t.Fatalf("skipped region %+v is not within the corrupted chunk [%d, %d)", region, offsets[1], offsets[2])
+			}
+			if !bytes.HasPrefix(decompressed, chunks[0]) {
+				t.Fatalf("the data before the corruption was not recovered")
+			}
+			tail := append(append([]byte{}, chunks[2]...), chunks[3]...)
+			if !bytes.HasSuffix(decompressed, tail) {
+				t.Fatalf("the data after the corruption was not recovered")
+			}
+		})
+	}
+}
+
+func TestRecoveryMultiMember(t *testing.T) {
+	chunks := make([][]byte, 4)
+	for i := range chunks {
+		chunks[i] = compressibleBytes(1 << 16)
+	}
+	opts := common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip)
+	first := compressWith(t, chunks[0], opts)
+	second, offsets := compressWithFullFlushes(t, chunks, opts)
+
+	// Corrupt the middle of the second chunk of the second member.
+	start := offsets[1] + (offsets[2]-offsets[1])/2
+	for i := start; i < start+64; i++ {
+		second[i] ^= 0xA5
+	}
+
+	var regions []common.SkippedRegion
+	decompressOpts := common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip).WithMultiMember(true).WithRecovery(func(region common.SkippedRegion) {
+		regions = append(regions, region)
+	})
+	r, err := zlib.NewDecompressReader(bytes.NewReader(append(first, second...)), decompressOpts)
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatalf("Error reading from decompress reader: %v", err)
+	}
+
+	if len(regions) != 1 {
+		t.Fatalf("expected 1 skipped region, got %d: %+v", len(regions), regions)
+	}
+	// The offset is in the whole compressed data, including the first member.
+	region := regions[0]
+	if region.Offset < int64(len(first)+start) || region.Offset+region.Length > int64(len(first)+offsets[2]) {
+		t.Fatalf("skipped region %+v is not within the corrupted chunk [%d, %d)", region, len(first)+offsets[1], len(first)+offsets[2])
+	}
+}
+
+func TestRecoveryUntilEnd(t *testing.T) {
+	chunks := [][]byte{compressibleBytes(1 << 16), compressibleBytes(1 << 16)}
+	compressed, offsets := compressWithFullFlushes(t, chunks, common.DefaultCompressOptions())
+
+	// Corrupt the last chunk so that there is no full flush point after the corruption.
+	start := offsets[1] + 100
+	for i := start; i < start+64; i++ {
+		compressed[i] ^= 0xA5
+	}
+
+	var regions []common.SkippedRegion
+	opts := common.DefaultDecompressOptions().WithRecovery(func(region common.SkippedRegion) {
+		regions = append(regions, region)
+	})
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), opts)
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	decompressed, err := io.ReadAll(r)
+	if err == nil {
+		t.Fatalf("expected an error when the input ends while skipping corrupted data")
+	}
+	if len(regions) != 1 || regions[0].Offset+regions[0].Length != int64(len(compressed)) {
+		t.Fatalf("expected 1 skipped region until the end of the input, got %+v", regions)
+	}
+	if !bytes.HasPrefix(decompressed, chunks[0]) {
+		t.Fatalf("the data before the corruption was not recovered")
+	}
+}
+
+func TestNoRecovery(t *testing.T) {
+	chunks := [][]byte{compressibleBytes(1 << 16), compressibleBytes(1 << 16), compressibleBytes(1 << 16)}
+	compressed, offsets := compressWithFullFlushes(t, chunks, common.DefaultCompressOptions())
+	start := offsets[1] + 100
+	for i := start; i < start+64; i++ {
+		compressed[i] ^= 0xA5
+	}
+
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions())
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	if _, err := io.ReadAll(r); err == nil {
+		t.Fatalf("expected an error without recovery")
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+type remainder interface {
+	Remaining() []byte
+}
+
+func TestRemaining(t *testing.T) {
+	input := compressibleBytes(100000)
+	trailer := []byte("trailing data after the stream")
+	compressed := append(compressWith(t, input, common.DefaultCompressOptions()), trailer...)
+
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions())
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Fatalf("decompressed data does not match the input")
+	}
+	if remaining := r.(remainder).Remaining(); !bytes.Equal(remaining, trailer) {
+		t.Fatalf("expected %q to remain, got %q", trailer, remaining)
+	}
+}
+
+func TestRemainingBufioReader(t *testing.T) {
+	input := compressibleBytes(100000)
+	trailer := []byte("trailing data after the stream")
+	for _, bufferSize := range []int{2, 7, 1024, 64 * 1024} {
+		for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeGzip, common.HeaderTypeRaw} {
+			compressed := compressWith(t, input, common.DefaultCompressOptions().WithHeader(header))
+			source := bufio.NewReader(bytes.NewReader(append(compressed, trailer...)))
+
+			r, err := zlib.NewDecompressReader(source, common.DefaultDecompressOptions().WithHeader(header).WithBufferSize(bufferSize))
+			if err != nil {
+				t.Fatalf("Error creating decompress reader: %v", err)
+			}
+			output, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Error decompressing with buffer size %d and header %v: %v", bufferSize, header, err)
+			}
+			if !bytes.Equal(output, input) {
+				t.Fatalf("decompressed data does not match the input with buffer size %d and header %v", bufferSize, header)
+			}
+			if n, err := r.Read(make([]byte, 10)); n != 0 || err != io.EOF {
+				t.Fatalf("expected EOF after the end of the stream, got %d, %v", n, err)
+			}
+
+			rest, err := io.ReadAll(source)
+			if err != nil {
+				t.Fatalf("Error reading the rest of the source: %v", err)
+			}
+			if !bytes.Equal(rest, trailer) {
+				t.Fatalf("expected the source to be positioned after the stream with buffer size %d and header %v, got %q", bufferSize, header, rest)
+			}
+		}
+	}
+}
+
+func TestRemainingReadAfterEOF(t *testing.T) {
+	input := compressibleBytes(100000)
+	trailer := RandBytes(10000)
+	compressed := compressWith(t, input, common.DefaultCompressOptions())
+	source := bytes.NewReader(append(compressed, trailer...))
+
+	r, err := zlib.NewDecompressReader(source, common.DefaultDecompressOptions().WithBufferSize(1024))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+	if !bytes.Equal(output, input) {
+		t.Fatalf("decompressed data does not match the input")
+	}
+	for i := 0; i < 2; i++ {
+		if n, err := r.Read(make([]byte, 10)); n != 0 || err != io.EOF {
+			t.Fatalf("expected EOF after the end of the stream, got %d, %v", n, err)
+		}
+	}
+
+	// The trailer is either remaining or still in the source.
+	rest, err := io.ReadAll(source)
+	if err != nil {
+		t.Fatalf("Error reading the rest of the source: %v", err)
+	}
+	if tail := append(r.(remainder).Remaining(), rest...); !bytes.Equal(tail, trailer) {
+		t.Fatalf("expected the trailer of %d bytes after the stream, got %d bytes", len(trailer), len(tail))
+	}
+}
+
+func TestDecompressWriterTrailingData(t *testing.T) {
+	input := compressibleBytes(100000)
+	trailer := []byte("trailing data after the stream")
+	compressed := compressWith(t, input, common.DefaultCompressOptions())
+	for _, bufferSize := range []int{7, 1024, 64 * 1024} {
+		var output bytes.Buffer
+		w, err := zlib.NewDecompressWriter(&output, common.DefaultDecompressOptions().WithBufferSize(bufferSize))
+		if err != nil {
+			t.Fatalf("Error creating decompress writer: %v", err)
+		}
+		n, err := w.Write(append(append([]byte(nil), compressed...), trailer...))
+		if n != len(compressed) || err != io.EOF {
+			t.Fatalf("expected %d bytes to be written up to the end of the stream with buffer size %d, got %d, %v", len(compressed), bufferSize, n, err)
+		}
+		if !bytes.Equal(output.Bytes(), input) {
+			t.Fatalf("decompressed data does not match the input with buffer size %d", bufferSize)
+		}
+		if _, err := w.Write(trailer); err == nil || err == io.EOF {
+			t.Fatalf("expected an error when writing after the end of the stream, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func getResetOptions() []common.CompressOptions {
+	return []common.CompressOptions{
+		common.DefaultCompressOptions(),
+		// Same options to reuse the zlib state
+		common.DefaultCompressOptions(),
+		common.DefaultCompressOptions().WithLevel(9).WithBufferSize(100),
+		common.DefaultCompressOptions().WithHeader(common.HeaderTypeRaw).WithWindowBits(10),
+		common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(&common.GzipHeader{Name: "reset"}),
+		common.DefaultCompressOptions().WithInitialDictionary([]byte("This is a dictionary")),
+	}
+}
+
+func TestResetCompressWriterDecompressReader(t *testing.T) {
+	for _, closeBeforeReset := range []bool{false, true} {
+		var compressed bytes.Buffer
+		var compressWriter common.WriteFlushCloser
+		var decompressReader io.ReadCloser
+		for i, opts := range getResetOptions() {
+			data := RandBytes(10 + 1<<15)
+			decompressOpts := matchCompressOptions(opts)
+
+			compressed.Reset()
+			var err error
+			if compressWriter == nil {
+				compressWriter, err = zlib.NewCompressWriter(&compressed, opts)
+			} else {
+				err = compressWriter.(common.CompressWriterResetter).Reset(&compressed, opts)
+			}
+			if err != nil {
+				t.Fatalf("%d: Error creating or resetting compress writer: %v", i, err)
+			}
+
+			if _, err := compressWriter.Write(data); err != nil {
+				t.Fatalf("%d: Error writing to compress writer: %v", i, err)
+			}
+			// The compress writer needs to be closed to conclude the stream.
+			if err := compressWriter.Close(); err != nil {
+				t.Fatalf("%d: Error closing compress writer: %v", i, err)
+			}
+
+			if decompressReader == nil {
+				decompressReader, err = zlib.NewDecompressReader(&compressed, decompressOpts)
+			} else {
+				err = decompressReader.(common.DecompressReaderResetter).Reset(&compressed, decompressOpts)
+			}
+			if err != nil {
+				t.Fatalf("%d: Error creating or resetting decompress reader: %v", i, err)
+			}
+
+			decompressed, err := io.ReadAll(decompressReader)
+			if err != nil {
+				t.Fatalf("%d: Error reading from decompress reader: %v", i, err)
+			}
+			if closeBeforeReset {
+				if err := decompressReader.Close(); err != nil {
+					t.Fatalf("%d: Error closing decompress reader: %v", i, err)
+				}
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Fatalf("%d: decompressed data is not equal to the original data", i)
+			}
+		}
+	}
+}
+
+func TestResetCompressReaderDecompressWriter(t *testing.T) {
+	for _, closeBeforeReset := range []bool{false, true} {
+		var compressReader io.ReadCloser
+		var decompressWriter common.WriteFlushCloser
+		for i, opts := range getResetOptions() {
+			data := RandBytes(10 + 1<<15)
+			decompressOpts := matchCompressOptions(opts)
+
+			var err error
+			if compressReader == nil {
+				compressReader, err = zlib.NewCompressReader(bytes.NewReader(data), opts)
+			} else {
+				err = compressReader.(common.CompressReaderResetter).Reset(bytes.NewReader(data), opts)
+			}
+			if err != nil {
+				t.Fatalf("%d: Error creating or resetting compress reader: %v", i, err)
+			}
+
+			compressed, err := io.ReadAll(compressReader)
+			if err != nil {
+				t.Fatalf("%d: Error reading from compress reader: %v", i, err)
+			}
+			if closeBeforeReset {
+				if err := compressReader.Close(); err != nil {
+					t.Fatalf("%d: Error closing compress reader: %v", i, err)
+				}
+			}
+
+			var decompressed bytes.Buffer
+			if decompressWriter == nil {
+				decompressWriter, err = zlib.NewDecompressWriter(&decompressed, decompressOpts)
+			} else {
+				err = decompressWriter.(common.DecompressWriterResetter).Reset(&decompressed, decompressOpts)
+			}
+			if err != nil {
+				t.Fatalf("%d: Error creating or resetting decompress writer: %v", i, err)
+			}
+
+			if _, err := decompressWriter.Write(compressed); err != nil && err != io.EOF {
+				t.Fatalf("%d: Error writing to decompress writer: %v", i, err)
+			}
+			if closeBeforeReset {
+				if err := decompressWriter.Close(); err != nil {
+					t.Fatalf("%d: Error closing decompress writer: %v", i, err)
+				}
+			}
+			if !bytes.Equal(decompressed.Bytes(), data) {
+				t.Fatalf("%d: decompressed data is not equal to the original data", i)
+			}
+		}
+	}
+}
+
+func TestResetInTheMiddleOfStream(t *testing.T) {
+	data := RandBytes(10 + 1<<15)
+	opts := common.DefaultCompressOptions()
+
+	var compressed bytes.Buffer
+	compressWriter, err := zlib.NewCompressWriter(&compressed, opts)
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := compressWriter.Write(RandBytes(1000)); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+
+	compressed.Reset()
+	if err := compressWriter.(common.CompressWriterResetter).Reset(&compressed, opts); err != nil {
+		t.Fatalf("Error resetting compress writer: %v", err)
+	}
+	if _, err := compressWriter.Write(data); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := compressWriter.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	decompressed, err := synchronousDecompressWriter(t, compressed.Bytes(), matchCompressOptions(opts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Fatalf("decompressed data is not equal to the original data")
+	}
+}
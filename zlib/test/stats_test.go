@@ -0,0 +1,115 @@
+package test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func TestCompressStats(t *testing.T) {
+	text := compressibleBytes(100000)
+	binary := make([]byte, 100000)
+	rand.Read(binary)
+	for _, header := range []common.HeaderType{common.HeaderTypeZlib, common.HeaderTypeGzip, common.HeaderTypeRaw} {
+		for _, data := range [][]byte{text, binary} {
+			var compressed bytes.Buffer
+			w, err := zlib.NewCompressWriter(&compressed, common.DefaultCompressOptions().WithHeader(header))
+			if err != nil {
+				t.Fatalf("Error creating compress writer: %v", err)
+			}
+			half := len(data) / 2
+			w.Write(data[:half])
+			w.Flush()
+			w.Write(data[half:])
+			w.Flush()
+			if err := w.Close(); err != nil {
+				t.Fatalf("Error closing compress writer: %v", err)
+			}
+
+			stats := w.(common.StatsReporter).Stats()
+			if stats.TotalIn != int64(len(data)) || stats.TotalOut != int64(compressed.Len()) {
+				t.Fatalf("expected totals %d and %d, got %+v", len(data), compressed.Len(), stats)
+			}
+			if stats.Ratio != float64(stats.TotalIn)/float64(stats.TotalOut) {
+				t.Fatalf("unexpected ratio: %+v", stats)
+			}
+			if stats.Flushes != 3 {
+				t.Fatalf("expected 2 flushes and the final one, got %d", stats.Flushes)
+			}
+			expectedChecksum := map[common.HeaderType]uint32{
+				common.HeaderTypeZlib: adler32.Checksum(data),
+				common.HeaderTypeGzip: crc32.ChecksumIEEE(data),
+			}[header]
+			if stats.Checksum != expectedChecksum {
+				t.Fatalf("expected checksum %08x for header %v, got %08x", expectedChecksum, header, stats.Checksum)
+			}
+			expectedDataType := common.DataTypeText
+			if bytes.Equal(data, binary) {
+				expectedDataType = common.DataTypeBinary
+			}
+			if stats.DataType != expectedDataType {
+				t.Fatalf("expected data type %v, got %v", expectedDataType, stats.DataType)
+			}
+		}
+	}
+}
+
+func TestDecompressStats(t *testing.T) {
+	parts := [][]byte{compressibleBytes(50000), compressibleBytes(1000), compressibleBytes(20000)}
+	var compressed, uncompressed []byte
+	for _, part := range parts {
+		compressed = append(compressed, compressWith(t, part, common.DefaultCompressOptions())...)
+		uncompressed = append(uncompressed, part...)
+	}
+
+	r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions().WithMultiMember(true))
+	if err != nil {
+		t.Fatalf("Error creating decompress reader: %v", err)
+	}
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatalf("Error decompressing: %v", err)
+	}
+
+	stats := r.(common.StatsReporter).Stats()
+	if stats.TotalIn != int64(len(compressed)) || stats.TotalOut != int64(len(uncompressed)) {
+		t.Fatalf("expected totals %d and %d across members, got %+v", len(compressed), len(uncompressed), stats)
+	}
+	if stats.Checksum != adler32.Checksum(parts[len(parts)-1]) {
+		t.Fatalf("expected the checksum of the last member, got %08x", stats.Checksum)
+	}
+	if stats.Ratio != float64(len(uncompressed))/float64(len(compressed)) {
+		t.Fatalf("unexpected ratio: %+v", stats)
+	}
+	if stats.PendingBytes != 0 || stats.PendingBits != 0 || stats.DataType != common.DataTypeUnknown {
+		t.Fatalf("unexpected stats for decompression: %+v", stats)
+	}
+}
+
+func TestDecompressStatsDataType(t *testing.T) {
+	data := compressibleBytes(10000)
+	for _, text := range []bool{false, true} {
+		compressed := compressWith(t, data, common.DefaultCompressOptions().WithHeader(common.HeaderTypeGzip).WithGzipHeader(&common.GzipHeader{Text: text}))
+		r, err := zlib.NewDecompressReader(bytes.NewReader(compressed), common.DefaultDecompressOptions().WithHeader(common.HeaderTypeGzip))
+		if err != nil {
+			t.Fatalf("Error creating decompress reader: %v", err)
+		}
+		if _, err := io.ReadAll(r); err != nil {
+			t.Fatalf("Error decompressing: %v", err)
+		}
+
+		expectedDataType := common.DataTypeUnknown
+		if text {
+			expectedDataType = common.DataTypeText
+		}
+		if stats := r.(common.StatsReporter).Stats(); stats.DataType != expectedDataType {
+			t.Fatalf("expected data type %v for a gzip header with text %v, got %v", expectedDataType, text, stats.DataType)
+		}
+		r.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+	"github.com/MeenaAlfons/go-zlib/zlib/dictionary"
+)
+
+func records(count int, seed int64) [][]byte {
+	random := rand.New(rand.NewSource(seed))
+	statuses := []string{"active", "suspended", "pending_verification", "deleted"}
+	var samples [][]byte
+	for i := 0; i < count; i++ {
+		record := fmt.Sprintf(`{"schema_version":3,"user_id":%d,"display_name":"user-%d","status":"%s","created_at":"2024-%02d-%02dT10:%02d:00Z","preferences":{"newsletter":%t,"theme":"dark","language":"en-US"}}`,
+			random.Intn(1000000), random.Intn(1000), statuses[random.Intn(len(statuses))], 1+random.Intn(12), 1+random.Intn(28), random.Intn(60), random.Intn(2) == 0)
+		samples = append(samples, []byte(record))
+	}
+	return samples
+}
+
+func TestTrain(t *testing.T) {
+	training := records(500, 1)
+	for _, maxSize := range []int{256, 4096, 1 << 20} {
+		dict := dictionary.Train(training, maxSize)
+		if len(dict) == 0 || len(dict) > min(maxSize, dictionary.MaxSize) {
+			t.Fatalf("expected a dictionary of at most %d bytes, got %d bytes", maxSize, len(dict))
+		}
+
+		samples := records(100, 2)
+		opts := common.DefaultCompressOptions().WithLevel(9).WithMemoryLevel(8)
+		gain, err := dictionary.Measure(dict, samples, opts)
+		if err != nil {
+			t.Fatalf("Error measuring the dictionary: %v", err)
+		}
+		if gain.Ratio() < 1.5 {
+			t.Fatalf("expected the dictionary of %d bytes to improve the ratio, got %+v with ratio %.2f", len(dict), gain, gain.Ratio())
+		}
+		if opts.InitialDictionary() != nil {
+			t.Fatalf("Measure modified the options")
+		}
+
+		compressed, err := zlib.Compress(nil, samples[0], common.DefaultCompressOptions().WithInitialDictionary(dict))
+		if err != nil {
+			t.Fatalf("Error compressing with the dictionary: %v", err)
+		}
+		decompressed, _, err := zlib.Uncompress(nil, compressed, common.DefaultDecompressOptions().WithInitialDictionary(dict))
+		if err != nil {
+			t.Fatalf("Error decompressing with the dictionary: %v", err)
+		}
+		if !bytes.Equal(decompressed, samples[0]) {
+			t.Fatalf("decompressed data does not match the sample")
+		}
+	}
+}
+
+func TestTrainWithoutSharedContent(t *testing.T) {
+	if dict := dictionary.Train(nil, 1024); dict != nil {
+		t.Fatalf("expected no dictionary without samples, got %d bytes", len(dict))
+	}
+	if dict := dictionary.Train([][]byte{RandBytes(1000), RandBytes(1000)}, 1024); dict != nil {
+		t.Fatalf("expected no dictionary for unrelated samples, got %d bytes", len(dict))
+	}
+	if dict := dictionary.Train(records(10, 1), 0); dict != nil {
+		t.Fatalf("expected no dictionary with maxSize 0, got %d bytes", len(dict))
+	}
+}
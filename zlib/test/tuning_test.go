@@ -0,0 +1,109 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MeenaAlfons/go-zlib/zlib"
+	"github.com/MeenaAlfons/go-zlib/zlib/common"
+)
+
+func compressibleBytes(size int) []byte {
+	words := [][]byte{[]byte("alpha "), []byte("beta "), []byte("gamma "), []byte("delta "), []byte("0000000000 ")}
+	random := RandBytes(size)
+	var data []byte
+	for i := 0; len(data) < size; i++ {
+		data = append(data, words[int(random[i])%len(words)]...)
+	}
+	return data[:size]
+}
+
+func compressWith(t *testing.T, data []byte, opts common.CompressOptions) []byte {
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, opts)
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+	return compressed.Bytes()
+}
+
+func TestProfiles(t *testing.T) {
+	data := compressibleBytes(1 << 18)
+	for _, profile := range common.Profiles {
+		t.Run(profile.Name, func(t *testing.T) {
+			opts := profile.Apply(common.DefaultCompressOptions())
+			compressed := compressWith(t, data, opts)
+			decompressed, err := stdDecompress(compressed, opts)
+			if err != nil {
+				t.Fatalf("Error decompressing: %v", err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Fatalf("decompressed data is not equal to the original data")
+			}
+		})
+	}
+}
+
+func TestProfileApplyCopiesTuning(t *testing.T) {
+	expected := *common.ProfileMaxRatio.Tuning
+	opts := common.ProfileMaxRatio.Apply(common.DefaultCompressOptions())
+	opts.Tuning().MaxChain = 1
+	if *common.ProfileMaxRatio.Tuning != expected {
+		t.Fatalf("changing the tuning of the options changed the profile to %+v", *common.ProfileMaxRatio.Tuning)
+	}
+}
+
+func TestTuningChangesOutput(t *testing.T) {
+	data := compressibleBytes(1 << 18)
+	opts := common.DefaultCompressOptions().WithLevel(9).WithMemoryLevel(8)
+	untuned := compressWith(t, data, opts)
+	tuned := compressWith(t, data, opts.WithTuning(&common.Tuning{GoodLength: 4, MaxLazy: 4, NiceLength: 8, MaxChain: 1}))
+	if bytes.Equal(untuned, tuned) {
+		t.Fatalf("tuning did not change the compressed output")
+	}
+	if len(tuned) <= len(untuned) {
+		t.Fatalf("expected a shorter search to compress worse: tuned %d, untuned %d", len(tuned), len(untuned))
+	}
+}
+
+func TestResetDropsTuning(t *testing.T) {
+	data := compressibleBytes(1 << 16)
+	untunedOpts := func() common.CompressOptions {
+		return common.DefaultCompressOptions().WithLevel(9).WithMemoryLevel(8)
+	}
+	tunedOpts := untunedOpts().WithTuning(&common.Tuning{GoodLength: 4, MaxLazy: 4, NiceLength: 8, MaxChain: 1})
+
+	var compressed bytes.Buffer
+	w, err := zlib.NewCompressWriter(&compressed, tunedOpts)
+	if err != nil {
+		t.Fatalf("Error creating compress writer: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	compressed.Reset()
+	if err := w.(common.CompressWriterResetter).Reset(&compressed, untunedOpts()); err != nil {
+		t.Fatalf("Error resetting compress writer: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Error writing to compress writer: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Error closing compress writer: %v", err)
+	}
+
+	expected := compressWith(t, data, untunedOpts())
+	if !bytes.Equal(compressed.Bytes(), expected) {
+		t.Fatalf("compressed output after Reset without tuning differs from a fresh writer")
+	}
+}
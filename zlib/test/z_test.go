@@ -43,7 +43,6 @@ import (
 // 6. BufferSize
 
 // TODO Create a test to verify the functionality of Flush vs Close on Writers.
-// TODO create a test to veify that windowbit can be set to 0 to request that  the window size in the zlib header of the compressed stream will be used.
 // TODO Add test cases for the error or failure cases.
 
 const REPEAT_COUNT = 1
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// globalLogger is the logger used by the streams that do not have their own logger.
+var globalLogger atomic.Pointer[slog.Logger]
+
+func init() {
+	globalLogger.Store(defaultLogger())
+}
+
+// SetLogger sets the global logger used by the streams that do not have their own logger.
+// nil disables logging.
+func SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(discardHandler{})
+	}
+	globalLogger.Store(logger)
+}
+
+// DebugLogger returns logger, or the global logger when logger is nil, if it is enabled for debug messages.
+// Otherwise, it returns nil. It is used to avoid building the attributes of messages that are not logged:
+//
+//	if logger := utils.DebugLogger(logger); logger != nil {
+//		logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+//	}
+func DebugLogger(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		logger = globalLogger.Load()
+	}
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return nil
+	}
+	return logger
+}
+
+// discardHandler is a slog.Handler that is never enabled.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
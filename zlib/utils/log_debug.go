@@ -4,9 +4,11 @@
 package utils
 
 import (
-	"log"
+	"log/slog"
+	"os"
 )
 
-func Debug(format string, args ...interface{}) {
-	log.Printf(format, args...)
+// defaultLogger logs debug messages to stderr when built with the debug tag.
+func defaultLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
 }
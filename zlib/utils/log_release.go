@@ -3,4 +3,9 @@
 
 package utils
 
-func Debug(fmt string, args ...interface{}) {}
+import "log/slog"
+
+// defaultLogger disables logging until a logger is set with SetLogger.
+func defaultLogger() *slog.Logger {
+	return slog.New(discardHandler{})
+}